@@ -7,31 +7,56 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/seo-platform/crawler/internal/adminapi"
 	"github.com/seo-platform/crawler/internal/crawler"
 	"github.com/seo-platform/crawler/internal/parser"
+	"github.com/seo-platform/crawler/internal/politeness"
 	"github.com/seo-platform/crawler/internal/queue"
+	"github.com/seo-platform/crawler/internal/snapshot"
+	"github.com/seo-platform/crawler/internal/stats"
 	"github.com/seo-platform/crawler/internal/storage"
+	"github.com/seo-platform/crawler/internal/warc"
 	"github.com/seo-platform/crawler/pkg/bloom"
 	"go.uber.org/zap"
 )
 
 type CrawlerService struct {
-	crawler      *crawler.Crawler
-	htmlParser   *parser.HTMLParser
+	crawler       *crawler.Crawler
+	htmlParser    *parser.HTMLParser
 	linkExtractor *parser.LinkExtractor
-	postgres     *storage.PostgresStorage
-	mongo        *storage.MongoStorage
-	kafkaQueue   *queue.KafkaQueue
-	deduplicator *bloom.URLDeduplicator
-	logger       *zap.Logger
+	postgres      *storage.PostgresStorage
+	mongo         *storage.MongoStorage
+	// scheduler is the backend-agnostic queue every enqueue and (for
+	// non-Kafka backends) every consume goes through, chosen by
+	// QUEUE_BACKEND the same way cmd/scheduler picks one.
+	scheduler queue.Scheduler
+	// kafkaQueue is non-nil only when QUEUE_BACKEND is "kafka" (the
+	// default). It's used instead of scheduler for publishing, so Kafka
+	// keeps its priority-lane topics, and for consuming, so Kafka keeps its
+	// retry-tier/DLQ routing on handler failure; neither exists on the
+	// plain Scheduler interface.
+	kafkaQueue     *queue.KafkaQueue
+	deduplicator   *bloom.URLDeduplicator
+	snapshotter    *snapshot.Snapshotter
+	snapshotStore  *snapshot.FSStore
+	warcWriter     *warc.Writer
+	politeness     *politeness.Controller
+	statsCollector *stats.Collector
+	logger         *zap.Logger
 }
 
+// statsFlushInterval is how often accumulated stats.Collector counters are
+// persisted to PostgreSQL and reset, bounding both query-time aggregation
+// cost and the collector's in-memory footprint.
+const statsFlushInterval = 1 * time.Minute
+
 func main() {
 	// Load environment variables
 	_ = godotenv.Load()
@@ -55,55 +80,117 @@ func main() {
 
 	mongoURL := getEnv("MONGO_URL", "mongodb://localhost:27017")
 	mongoDb := getEnv("MONGO_DATABASE", "seo_crawler")
-	mg, err := storage.NewMongoStorage(mongoURL, mongoDb, logger)
+	gridfsThreshold, _ := strconv.ParseInt(getEnv("GRIDFS_THRESHOLD_BYTES", ""), 10, 64)
+	mg, err := storage.NewMongoStorage(mongoURL, mongoDb, gridfsThreshold, logger)
 	if err != nil {
 		logger.Fatal("failed to connect to MongoDB", zap.Error(err))
 	}
 	defer mg.Close(context.Background())
 
-	// Initialize Kafka queue
-	kafkaBrokers := strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ",")
-	kafkaTopic := getEnv("KAFKA_TOPIC", "crawl-jobs")
-	kafkaGroup := getEnv("KAFKA_CONSUMER_GROUP", "crawler-workers")
-
-	kq := queue.NewKafkaQueue(queue.KafkaConfig{
-		Brokers:       kafkaBrokers,
-		Topic:         kafkaTopic,
-		ConsumerGroup: kafkaGroup,
-		BatchSize:     100,
-		BatchTimeout:  1 * time.Second,
-	}, logger)
-	defer kq.Close()
+	// Initialize the crawl-job queue backend. QUEUE_BACKEND picks between
+	// Kafka (the default, for production, with its priority-lane topics and
+	// retry/DLQ routing), Redis, and an in-process memory backend, the same
+	// choice cmd/scheduler's newScheduler offers.
+	var kq *queue.KafkaQueue
+	var sched queue.Scheduler
+	switch getEnv("QUEUE_BACKEND", "kafka") {
+	case "redis":
+		redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+		sched = queue.NewRedisScheduler(queue.RedisConfig{
+			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       redisDB,
+		}, logger)
+	case "memory":
+		sched = queue.NewMemoryScheduler()
+	default:
+		kafkaBrokers := strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ",")
+		kafkaTopic := getEnv("KAFKA_TOPIC", "crawl-jobs")
+		kafkaGroup := getEnv("KAFKA_CONSUMER_GROUP", "crawler-workers")
+
+		kq, err = queue.NewKafkaQueue(queue.KafkaConfig{
+			Brokers:       kafkaBrokers,
+			Topic:         kafkaTopic,
+			ConsumerGroup: kafkaGroup,
+			BatchSize:     100,
+			BatchTimeout:  1 * time.Second,
+		}, logger)
+		if err != nil {
+			logger.Fatal("failed to create Kafka queue", zap.Error(err))
+		}
+	}
+	if kq != nil {
+		defer kq.Close()
+	} else {
+		defer sched.Close()
+	}
 
 	// Initialize crawler
+	onionRateLimitPerSec, _ := strconv.ParseFloat(getEnv("ONION_RATE_LIMIT_PER_SEC", "0.1"), 64)
+
 	crawlerConfig := crawler.CrawlerConfig{
-		UserAgent:        getEnv("USER_AGENT", "SEO-Intelligence-Bot/1.0"),
-		RespectRobots:    getEnv("RESPECT_ROBOTS", "true") == "true",
-		MaxDepth:         5,
-		MaxConcurrency:   10,
-		RequestTimeout:   30 * time.Second,
-		RateLimitPerSec:  1.0,
-		FollowRedirects:  true,
+		UserAgent:            getEnv("USER_AGENT", "SEO-Intelligence-Bot/1.0"),
+		RespectRobots:        getEnv("RESPECT_ROBOTS", "true") == "true",
+		MaxDepth:             5,
+		MaxConcurrency:       10,
+		RequestTimeout:       30 * time.Second,
+		RateLimitPerSec:      1.0,
+		FollowRedirects:      true,
+		ProxyURL:             getEnv("PROXY_URL", ""),
+		AllowOnion:           getEnv("ALLOW_ONION", "false") == "true",
+		OnionRateLimitPerSec: onionRateLimitPerSec,
 	}
 
 	c := crawler.NewCrawler(crawlerConfig, logger)
+	defer c.Close()
+
+	statsCollector := stats.NewCollector()
+	c.SetCollector(statsCollector)
 
 	// Initialize parsers
 	htmlParser := parser.NewHTMLParser(logger)
 	linkExtractor := parser.NewLinkExtractor(logger)
 
 	// Initialize URL deduplicator
-	dedup := bloom.NewURLDeduplicator(1000000, 0.01, logger)
+	dedup := bloom.NewURLDeduplicator(bloom.URLDeduplicatorConfig{
+		InitialCapacity:   1000000,
+		FalsePositiveRate: 0.01,
+	}, logger)
+
+	// Initialize page snapshot archiver
+	snapshotter := snapshot.NewSnapshotter(c.HTTPClient(), c.RobotsCache(), c.RateLimiter(), c.UserAgent(), logger)
+	snapshotDir := getEnv("SNAPSHOT_DIR", "./snapshots")
+	snapshotStore := snapshot.NewFSStore(snapshotDir, logger)
+
+	// Initialize WARC archiver
+	warcMaxSize, _ := strconv.ParseInt(getEnv("WARC_MAX_SIZE_BYTES", ""), 10, 64)
+	warcWriter, err := warc.NewWriter(getEnv("WARC_DIR", "./archives"), warcMaxSize, logger)
+	if err != nil {
+		logger.Fatal("failed to initialize WARC writer", zap.Error(err))
+	}
+	defer warcWriter.Close()
+
+	// Initialize per-host adaptive politeness controller
+	politenessController := politeness.NewController(politeness.ControllerConfig{
+		DefaultRate:  crawlerConfig.RateLimitPerSec,
+		DefaultBurst: 3,
+	})
 
 	service := &CrawlerService{
-		crawler:       c,
-		htmlParser:    htmlParser,
-		linkExtractor: linkExtractor,
-		postgres:      pg,
-		mongo:         mg,
-		kafkaQueue:    kq,
-		deduplicator:  dedup,
-		logger:        logger,
+		crawler:        c,
+		htmlParser:     htmlParser,
+		linkExtractor:  linkExtractor,
+		postgres:       pg,
+		mongo:          mg,
+		scheduler:      sched,
+		kafkaQueue:     kq,
+		deduplicator:   dedup,
+		snapshotter:    snapshotter,
+		snapshotStore:  snapshotStore,
+		warcWriter:     warcWriter,
+		politeness:     politenessController,
+		statsCollector: statsCollector,
+		logger:         logger,
 	}
 
 	// Start HTTP server for API
@@ -113,6 +200,9 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Periodically flush accumulated crawl stats to PostgreSQL
+	go service.runStatsFlusher(ctx)
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -149,9 +239,33 @@ func (s *CrawlerService) startHTTPServer() {
 	// Get sitemap
 	router.GET("/sitemap", s.handleGetSitemap)
 
+	// Get feed
+	router.GET("/feed", s.handleGetFeed)
+
 	// Stats
 	router.GET("/stats", s.handleGetStats)
 
+	// List archived WARC files
+	router.GET("/archives", s.handleGetArchives)
+
+	// Per-priority-lane queue depth estimates
+	router.GET("/frontier", s.handleGetFrontier)
+
+	// Retry-tier and DLQ queue depth estimates
+	router.GET("/stats/retry", s.handleGetRetryStats)
+
+	// Per-domain and per-job crawl outcome counters
+	router.GET("/stats/domains", s.handleGetDomainStats)
+	router.GET("/stats/jobs/:id", s.handleGetJobStats)
+
+	// Operator-only admin API (cache invalidation, rate overrides, job
+	// control, live client config) gated behind a shared secret.
+	if adminSecret := getEnv("ADMIN_SECRET", ""); adminSecret != "" {
+		adminapi.NewServer(s.crawler, s.postgres, s.logger, adminSecret).RegisterRoutes(router)
+	} else {
+		s.logger.Warn("ADMIN_SECRET not set, admin API disabled")
+	}
+
 	port := getEnv("PORT", "8080")
 	s.logger.Info("starting HTTP server", zap.String("port", port))
 
@@ -162,9 +276,12 @@ func (s *CrawlerService) startHTTPServer() {
 
 func (s *CrawlerService) handleStartCrawl(c *gin.Context) {
 	var req struct {
-		URL      string `json:"url" binding:"required"`
-		MaxDepth int    `json:"max_depth"`
-		Priority int    `json:"priority"`
+		URL           string `json:"url" binding:"required"`
+		MaxDepth      int    `json:"max_depth"`
+		Priority      int    `json:"priority"`
+		Proxy         string `json:"proxy"`
+		Onion         bool   `json:"onion"`
+		ArchiveFormat string `json:"archive_format"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -172,7 +289,15 @@ func (s *CrawlerService) handleStartCrawl(c *gin.Context) {
 		return
 	}
 
-	// Parse URL
+	// Normalize so syntactic variants of the same URL (scheme case, default
+	// port, dot segments, tracking params, ...) land on the same crawl job
+	normalizedURL, err := s.crawler.URLNormalizer().Normalize(req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid URL"})
+		return
+	}
+	req.URL = normalizedURL
+
 	parsedURL, err := url.Parse(req.URL)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid URL"})
@@ -200,6 +325,25 @@ func (s *CrawlerService) handleStartCrawl(c *gin.Context) {
 		return
 	}
 
+	// A per-request proxy/onion override is recorded as job metadata rather
+	// than spun up as a dedicated HTTP client: the shared crawler's transport
+	// is configured once at startup (PROXY_URL/ALLOW_ONION) for operational
+	// simplicity, and this metadata lets the consumer flag and log jobs that
+	// were explicitly scheduled as Tor jobs.
+	var metadata map[string]string
+	if req.Proxy != "" || req.Onion || req.ArchiveFormat != "" {
+		metadata = map[string]string{}
+		if req.Proxy != "" {
+			metadata["proxy"] = req.Proxy
+		}
+		if req.Onion {
+			metadata["onion"] = "true"
+		}
+		if req.ArchiveFormat != "" {
+			metadata["archive_format"] = req.ArchiveFormat
+		}
+	}
+
 	// Publish to Kafka
 	msg := &queue.CrawlMessage{
 		JobID:    jobID,
@@ -207,9 +351,10 @@ func (s *CrawlerService) handleStartCrawl(c *gin.Context) {
 		Depth:    0,
 		MaxDepth: req.MaxDepth,
 		Priority: req.Priority,
+		Metadata: metadata,
 	}
 
-	if err := s.kafkaQueue.PublishCrawlJob(c.Request.Context(), msg); err != nil {
+	if err := s.enqueue(c.Request.Context(), msg); err != nil {
 		s.logger.Error("failed to publish crawl job", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue job"})
 		return
@@ -286,26 +431,161 @@ func (s *CrawlerService) handleGetSitemap(c *gin.Context) {
 	})
 }
 
+func (s *CrawlerService) handleGetFeed(c *gin.Context) {
+	feedURL := c.Query("url")
+	if feedURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url parameter required"})
+		return
+	}
+
+	result, err := s.crawler.ParseFeed(c.Request.Context(), feedURL)
+	if err != nil {
+		s.logger.Error("failed to parse feed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"feed":  feedURL,
+		"title": result.Title,
+		"count": len(result.Items),
+		"items": result.Items,
+	})
+}
+
 func (s *CrawlerService) handleGetStats(c *gin.Context) {
 	dedupStats := s.deduplicator.Stats()
-	kafkaStats := s.kafkaQueue.Stats()
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"deduplicator": dedupStats,
-		"kafka": gin.H{
-			"writes": kafkaStats.Writes,
+		"politeness":   s.politeness.Stats(),
+	}
+	if s.kafkaQueue != nil {
+		kafkaStats := s.kafkaQueue.Stats()
+		resp["kafka"] = gin.H{
+			"writes":   kafkaStats.Writes,
 			"messages": kafkaStats.Messages,
-			"errors": kafkaStats.Errors,
-		},
+			"errors":   kafkaStats.Errors,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *CrawlerService) handleGetArchives(c *gin.Context) {
+	paths, err := s.warcWriter.List()
+	if err != nil {
+		s.logger.Error("failed to list WARC archives", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list archives"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(paths),
+		"archives": paths,
 	})
 }
 
-func (s *CrawlerService) consumeJobs(ctx context.Context) error {
-	return s.kafkaQueue.ConsumeCrawlJobs(ctx, func(msg *queue.CrawlMessage) error {
-		return s.processCrawlJob(ctx, msg)
+func (s *CrawlerService) handleGetFrontier(c *gin.Context) {
+	if s.kafkaQueue == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "priority-lane frontier stats are only available on the kafka backend"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"lanes": s.kafkaQueue.FrontierStats(),
+	})
+}
+
+func (s *CrawlerService) handleGetRetryStats(c *gin.Context) {
+	if s.kafkaQueue == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "retry-tier/DLQ stats are only available on the kafka backend"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"tiers": s.kafkaQueue.RetryStats(),
+		"dlq":   s.kafkaQueue.DLQDepth(),
 	})
 }
 
+func (s *CrawlerService) handleGetDomainStats(c *gin.Context) {
+	rows, err := s.postgres.ListDomainStats(c.Request.Context())
+	if err != nil {
+		s.logger.Error("failed to list domain stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list domain stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"domains": rows})
+}
+
+func (s *CrawlerService) handleGetJobStats(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+
+	row, err := s.postgres.GetJobStats(c.Request.Context(), jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no stats for job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, row)
+}
+
+func (s *CrawlerService) consumeJobs(ctx context.Context) error {
+	if s.kafkaQueue != nil {
+		return s.kafkaQueue.ConsumeCrawlJobs(ctx, func(msg *queue.CrawlMessage) error {
+			return s.processCrawlJob(ctx, msg)
+		})
+	}
+
+	// Redis and memory backends have no Kafka-style retry-tier/DLQ topics,
+	// so a failed job is just Nack'd back onto the same queue for
+	// redelivery instead of being routed to a tier.
+	for {
+		msg, err := s.scheduler.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.logger.Error("failed to dequeue crawl job", zap.Error(err))
+			continue
+		}
+
+		if procErr := s.processCrawlJob(ctx, msg); procErr != nil {
+			s.logger.Error("failed to process crawl job", zap.Error(procErr), zap.String("url", msg.URL))
+			if nackErr := s.scheduler.Nack(ctx, msg); nackErr != nil {
+				s.logger.Error("failed to nack crawl job", zap.Error(nackErr))
+			}
+			continue
+		}
+
+		if ackErr := s.scheduler.Ack(ctx, msg); ackErr != nil {
+			s.logger.Error("failed to ack crawl job", zap.Error(ackErr))
+		}
+	}
+}
+
+// enqueue publishes msg via whichever queue backend is configured: Kafka's
+// own PublishCrawlJob (so priority-lane routing keeps working) when
+// QUEUE_BACKEND is "kafka", or the generic Scheduler otherwise.
+func (s *CrawlerService) enqueue(ctx context.Context, msg *queue.CrawlMessage) error {
+	if s.kafkaQueue != nil {
+		return s.kafkaQueue.PublishCrawlJob(ctx, msg)
+	}
+	return s.scheduler.Enqueue(ctx, msg)
+}
+
+// enqueueBatch is enqueue for a batch of messages.
+func (s *CrawlerService) enqueueBatch(ctx context.Context, msgs []*queue.CrawlMessage) error {
+	if s.kafkaQueue != nil {
+		return s.kafkaQueue.PublishCrawlJobBatch(ctx, msgs)
+	}
+	return s.scheduler.EnqueueBatch(ctx, msgs)
+}
+
 func (s *CrawlerService) processCrawlJob(ctx context.Context, msg *queue.CrawlMessage) error {
 	s.logger.Info("processing crawl job",
 		zap.Int64("job_id", msg.JobID),
@@ -319,25 +599,91 @@ func (s *CrawlerService) processCrawlJob(ctx context.Context, msg *queue.CrawlMe
 		return nil
 	}
 
+	// Respect any politeness delay this job was previously requeued with
+	if deferred := s.deferToPoliteness(ctx, msg, time.Until(msg.NotBefore)); deferred {
+		return nil
+	}
+
+	host := politeness.RegistrableDomain(extractDomain(msg.URL))
+
+	if delay, err := s.crawler.RobotsCache().GetCrawlDelay(ctx, msg.URL, s.crawler.UserAgent()); err == nil && delay > 0 {
+		s.politeness.SetCrawlDelay(host, delay)
+	}
+
+	// Requeue the job with NotBefore set if the host needs longer than
+	// politenessRequeueThreshold before its next request, rather than
+	// blocking this worker on it; a shorter wait is slept through here,
+	// before the fetch below waits on s.crawler's own rateLimiter (which
+	// Crawl also applies the robots.txt Crawl-delay to). Nothing takes a
+	// token from politeness's bucket, so its wait here is its 429/503
+	// backoff delay.
+	if deferred := s.deferToPoliteness(ctx, msg, s.politeness.PeekWait(host)); deferred {
+		return nil
+	}
+
 	// Update job status
 	if err := s.postgres.UpdateCrawlJobStatus(ctx, msg.JobID, "processing", nil); err != nil {
 		s.logger.Error("failed to update job status", zap.Error(err))
 	}
 
+	// Revalidate with the server's last-known validators, if any, so an
+	// unchanged page costs a 304 instead of a full re-download
+	var hints []crawler.RevalidateHints
+	if etag, lastModified, err := s.postgres.GetRevalidationHeaders(ctx, msg.URL); err != nil {
+		s.logger.Warn("failed to load revalidation headers", zap.String("url", msg.URL), zap.Error(err))
+	} else if etag != "" || lastModified != "" {
+		hints = append(hints, crawler.RevalidateHints{ETag: etag, LastModified: lastModified})
+	}
+
 	// Crawl the URL
-	result, err := s.crawler.Crawl(ctx, msg.URL, msg.Depth)
+	result, err := s.crawler.Crawl(ctx, msg.URL, msg.Depth, hints...)
 	if err != nil {
 		s.logger.Error("crawl failed", zap.Error(err), zap.String("url", msg.URL))
 		errMsg := err.Error()
 		_ = s.postgres.UpdateCrawlJobStatus(ctx, msg.JobID, "failed", &errMsg)
+		s.statsCollector.RecordJob(msg.JobID, parentJobIDFromMetadata(msg.Metadata), stats.ClassifyOutcome(0, err), 0, 0)
 		return err
 	}
+	s.statsCollector.RecordJob(msg.JobID, parentJobIDFromMetadata(msg.Metadata), stats.ClassifyOutcome(result.StatusCode, nil), int64(len(result.Content)), result.Duration)
+
+	s.politeness.ReportResponse(host, result.StatusCode, politeness.ParseRetryAfter(firstHeader(result.Headers, "Retry-After")))
 
 	// Mark as seen
 	s.deduplicator.MarkSeen(msg.URL)
 
-	// Parse HTML if applicable
-	if result.StatusCode == 200 && crawler.IsHTMLContent(result.ContentType) {
+	// Archive every fetch (both primary pages and related assets) as a WARC
+	// request/response record pair, independent of the PostgreSQL/MongoDB
+	// writes below, so the raw bytes can be replayed later. A failed write
+	// aborts the job the same way a failed crawl does above, rather than
+	// silently producing a gap in the archive.
+	if err := s.warcWriter.WriteFetch(warc.PageFetch{
+		URL:            result.URL,
+		RequestLine:    result.RequestLine,
+		RequestHeaders: result.RequestHeaders,
+		StatusCode:     result.StatusCode,
+		ContentType:    result.ContentType,
+		Headers:        result.Headers,
+		Body:           result.Content,
+		RemoteAddr:     result.RemoteAddr,
+		FetchedAt:      result.CrawledAt,
+	}); err != nil {
+		s.logger.Error("failed to write WARC record", zap.String("url", result.URL), zap.Error(err))
+		errMsg := err.Error()
+		_ = s.postgres.UpdateCrawlJobStatus(ctx, msg.JobID, "failed", &errMsg)
+		return fmt.Errorf("failed to write WARC record: %w", err)
+	}
+
+	// A 304 confirms the page is unchanged: just bump last_seen_at and skip
+	// re-parsing entirely, so it isn't mistaken for a content change.
+	if result.StatusCode == http.StatusNotModified {
+		if err := s.postgres.TouchPageLastSeen(ctx, result.URL); err != nil {
+			s.logger.Warn("failed to touch page last seen", zap.String("url", result.URL), zap.Error(err))
+		}
+	} else if !msg.Related && result.StatusCode == 200 && crawler.IsHTMLContent(result.ContentType) {
+		// Parse HTML if applicable. Related-resource jobs (assets fetched
+		// purely so an archived page renders correctly) never expand
+		// further, even if the fetched resource turns out to be HTML itself
+		// (e.g. an iframe src).
 		if err := s.processHTMLPage(ctx, result, msg); err != nil {
 			s.logger.Error("failed to process HTML", zap.Error(err))
 		}
@@ -357,14 +703,31 @@ func (s *CrawlerService) processCrawlJob(ctx context.Context, msg *queue.CrawlMe
 }
 
 func (s *CrawlerService) processHTMLPage(ctx context.Context, result *crawler.CrawlResult, msg *queue.CrawlMessage) error {
+	// Capture and archive a snapshot so we can answer "what changed?" later
+	snap, err := s.snapshotter.Capture(ctx, result.URL, result.Content, true)
+	if err != nil {
+		s.logger.Warn("failed to capture snapshot", zap.String("url", result.URL), zap.Error(err))
+	} else {
+		if _, err := s.snapshotStore.Save(snap); err != nil {
+			s.logger.Warn("failed to save snapshot", zap.String("url", result.URL), zap.Error(err))
+		}
+
+		changed, err := s.postgres.CheckContentChange(ctx, result.URL, result.ContentHash)
+		if err == nil && !changed {
+			s.logger.Info("page unchanged since last crawl, skipping reprocessing", zap.String("url", result.URL))
+			return nil
+		}
+	}
+
 	// Parse HTML
 	parsed, err := s.htmlParser.Parse(result.Content, result.URL)
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
-	// Extract links
-	extracted := s.linkExtractor.Extract(parsed.Links, result.URL)
+	// Extract primary links and related resources (assets needed to render
+	// the page) as one unified, tagged list
+	extracted := s.linkExtractor.Extract(parsed.Links, parsed.Assets, result.URL)
 
 	// Count internal/external links
 	internalCount := 0
@@ -398,6 +761,8 @@ func (s *CrawlerService) processHTMLPage(ctx context.Context, result *crawler.Cr
 		ExternalLinks: externalCount,
 		LoadTime:      int(result.Duration.Milliseconds()),
 		CrawledAt:     result.CrawledAt,
+		ETag:          firstHeader(result.Headers, "Etag"),
+		LastModified:  firstHeader(result.Headers, "Last-Modified"),
 	}
 
 	if err := s.postgres.SavePageMetadata(ctx, metadata); err != nil {
@@ -418,35 +783,89 @@ func (s *CrawlerService) processHTMLPage(ctx context.Context, result *crawler.Cr
 		return fmt.Errorf("failed to save content: %w", err)
 	}
 
-	// Queue child URLs if within depth limit
-	if msg.Depth < msg.MaxDepth {
-		s.queueChildURLs(ctx, extracted, msg)
-	}
+	// Queue child URLs: primary links respect MaxDepth, related resources
+	// are always queued once so the archived page renders correctly
+	s.queueChildURLs(ctx, extracted, msg)
 
 	return nil
 }
 
+// canonicalize runs urlStr through the shared URLNormalizer, falling back to
+// urlStr unchanged if it fails to parse (the dedup/onion-scope checks that
+// follow can reject it on their own terms instead).
+func (s *CrawlerService) canonicalize(urlStr string) string {
+	normalized, err := s.crawler.URLNormalizer().Normalize(urlStr)
+	if err != nil {
+		s.logger.Warn("failed to normalize URL", zap.String("url", urlStr), zap.Error(err))
+		return urlStr
+	}
+	return normalized
+}
+
 func (s *CrawlerService) queueChildURLs(ctx context.Context, links []parser.ExtractedLink, parentMsg *queue.CrawlMessage) {
-	internal := s.linkExtractor.FilterInternalLinks(links)
-	follow := s.linkExtractor.FilterFollowLinks(internal)
-	unique := s.linkExtractor.DeduplicateLinks(follow)
+	var primary, related []parser.ExtractedLink
+	for _, link := range links {
+		if link.Tag == parser.TagRelated {
+			related = append(related, link)
+		} else {
+			primary = append(primary, link)
+		}
+	}
+
+	parentIsOnion := parser.IsOnionHost(extractDomain(parentMsg.URL))
 
 	var messages []*queue.CrawlMessage
-	for _, link := range unique {
-		if s.deduplicator.IsSeen(link.URL) {
+
+	if parentMsg.Depth < parentMsg.MaxDepth {
+		internal := s.linkExtractor.FilterInternalLinks(primary)
+		follow := s.linkExtractor.FilterFollowLinks(internal)
+		unique := s.linkExtractor.DeduplicateLinks(follow)
+
+		for _, link := range unique {
+			childURL := s.canonicalize(link.URL)
+			if s.deduplicator.IsSeen(childURL) {
+				continue
+			}
+			if !s.inOnionScope(parentIsOnion, childURL) {
+				continue
+			}
+
+			messages = append(messages, &queue.CrawlMessage{
+				URL:      childURL,
+				Depth:    parentMsg.Depth + 1,
+				MaxDepth: parentMsg.MaxDepth,
+				Priority: decayPriority(parentMsg.Priority),
+			})
+		}
+	}
+
+	// Related resources bypass the MaxDepth check and external-domain
+	// filtering entirely: a page's assets must be fetched regardless of how
+	// deep it was found or whether they live on another host, or the
+	// archived page won't render correctly. They still respect onion scope,
+	// though, so a clearnet page's onion-hosted asset (or vice versa) isn't
+	// silently fetched across the boundary. They carry Related so the
+	// consumer never expands links from them.
+	for _, link := range s.linkExtractor.DeduplicateLinks(related) {
+		childURL := s.canonicalize(link.URL)
+		if s.deduplicator.IsSeen(childURL) {
+			continue
+		}
+		if !s.inOnionScope(parentIsOnion, childURL) {
 			continue
 		}
 
 		messages = append(messages, &queue.CrawlMessage{
-			URL:      link.URL,
-			Depth:    parentMsg.Depth + 1,
+			URL:      childURL,
+			Depth:    parentMsg.Depth,
 			MaxDepth: parentMsg.MaxDepth,
 			Priority: parentMsg.Priority,
+			Related:  true,
 		})
 	}
 
 	if len(messages) > 0 {
-		if err := s.kafkaQueue.PublishCrawlJobBatch(ctx, messages); err != nil {
+		if err := s.enqueueBatch(ctx, messages); err != nil {
 			s.logger.Error("failed to queue child URLs", zap.Error(err))
 		} else {
 			s.logger.Info("queued child URLs", zap.Int("count", len(messages)))
@@ -454,6 +873,59 @@ func (s *CrawlerService) queueChildURLs(ctx context.Context, links []parser.Extr
 	}
 }
 
+// inOnionScope reports whether a child URL may be queued given the parent
+// page's onion-ness: unless the crawler is explicitly configured to allow
+// it, a crawl never crosses the onion/clearnet boundary in either direction.
+func (s *CrawlerService) inOnionScope(parentIsOnion bool, childURL string) bool {
+	if s.crawler.AllowOnion() {
+		return true
+	}
+	return parser.IsOnionHost(extractDomain(childURL)) == parentIsOnion
+}
+
+// parentJobIDFromMetadata parses the "parent_job_id" metadata key set on
+// sitemap-crawl child jobs, returning 0 (no parent) for a standalone URL
+// crawl or an unparseable value.
+func parentJobIDFromMetadata(metadata map[string]string) int64 {
+	id, _ := strconv.ParseInt(metadata["parent_job_id"], 10, 64)
+	return id
+}
+
+// runStatsFlusher periodically persists s.statsCollector's accumulated
+// counters to PostgreSQL and resets it, until ctx is done.
+func (s *CrawlerService) runStatsFlusher(ctx context.Context) {
+	ticker := time.NewTicker(statsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushStats(ctx)
+		}
+	}
+}
+
+func (s *CrawlerService) flushStats(ctx context.Context) {
+	windowEnd := time.Now()
+	windowStart := windowEnd.Add(-statsFlushInterval)
+
+	for _, d := range s.statsCollector.DomainSnapshots() {
+		if err := s.postgres.SaveDomainStatsSnapshot(ctx, d.Domain, windowStart, windowEnd, d.Snapshot); err != nil {
+			s.logger.Error("failed to save domain stats snapshot", zap.String("domain", d.Domain), zap.Error(err))
+		}
+	}
+
+	for _, j := range s.statsCollector.JobSnapshots() {
+		if err := s.postgres.SaveJobStatsSnapshot(ctx, windowStart, windowEnd, j); err != nil {
+			s.logger.Error("failed to save job stats snapshot", zap.Int64("job_id", j.JobID), zap.Error(err))
+		}
+	}
+
+	s.statsCollector.Reset()
+}
+
 func extractDomain(urlStr string) string {
 	parsed, err := url.Parse(urlStr)
 	if err != nil {
@@ -462,6 +934,69 @@ func extractDomain(urlStr string) string {
 	return parsed.Host
 }
 
+// decayPriority returns a child URL's priority given its parent's: deeper
+// pages matter less than the seed that discovered them, so priority steps
+// down by one per level, floored at 0 (the lowest lane) rather than going
+// negative.
+func decayPriority(parentPriority int) int {
+	if parentPriority <= 0 {
+		return 0
+	}
+	return parentPriority - 1
+}
+
+// politenessRequeueThreshold is the longest wait processCrawlJob will hold a
+// consumer goroutine for in-process. Longer waits republish the job with
+// NotBefore set instead. RedisScheduler holds such a job in its delayed set
+// until NotBefore; the Kafka and memory backends redeliver it straight away,
+// and processCrawlJob defers it again until the remaining wait is short.
+const politenessRequeueThreshold = 5 * time.Second
+
+// deferToPoliteness waits out wait in-process if it's short, or republishes
+// msg with NotBefore set and returns true if it's long enough that holding a
+// consumer goroutine would be wasteful. Callers must stop processing msg
+// when deferred is true.
+func (s *CrawlerService) deferToPoliteness(ctx context.Context, msg *queue.CrawlMessage, wait time.Duration) (deferred bool) {
+	if wait <= 0 {
+		return false
+	}
+
+	if wait <= politenessRequeueThreshold {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return true
+		}
+	}
+
+	requeued := *msg
+	requeued.NotBefore = time.Now().Add(wait)
+	if err := s.enqueue(ctx, &requeued); err != nil {
+		s.logger.Error("failed to requeue job for politeness delay",
+			zap.String("url", msg.URL),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	s.logger.Info("requeued job to respect politeness delay",
+		zap.String("url", msg.URL),
+		zap.Duration("wait", wait),
+	)
+	return true
+}
+
+// firstHeader returns the first value for key in headers, or "" if absent
+func firstHeader(headers map[string][]string, key string) string {
+	if values, ok := headers[key]; ok && len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
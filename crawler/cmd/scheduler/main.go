@@ -7,23 +7,28 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/seo-platform/crawler/internal/auth"
 	"github.com/seo-platform/crawler/internal/crawler"
 	"github.com/seo-platform/crawler/internal/queue"
+	"github.com/seo-platform/crawler/internal/recrawl"
 	"github.com/seo-platform/crawler/internal/storage"
 	"go.uber.org/zap"
 )
 
 type SchedulerService struct {
-	crawler    *crawler.Crawler
-	postgres   *storage.PostgresStorage
-	kafkaQueue *queue.KafkaQueue
-	logger     *zap.Logger
+	crawler     *crawler.Crawler
+	postgres    *storage.PostgresStorage
+	mongo       *storage.MongoStorage
+	scheduler   queue.Scheduler
+	authLimiter *auth.Limiter
+	logger      *zap.Logger
 }
 
 func main() {
@@ -47,17 +52,24 @@ func main() {
 	}
 	defer pg.Close()
 
-	// Initialize Kafka queue
-	kafkaBrokers := strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ",")
-	kafkaTopic := getEnv("KAFKA_TOPIC", "crawl-jobs")
+	mongoURL := getEnv("MONGO_URL", "mongodb://localhost:27017")
+	mongoDb := getEnv("MONGO_DATABASE", "seo_crawler")
+	gridfsThreshold, _ := strconv.ParseInt(getEnv("GRIDFS_THRESHOLD_BYTES", ""), 10, 64)
+	mg, err := storage.NewMongoStorage(mongoURL, mongoDb, gridfsThreshold, logger)
+	if err != nil {
+		logger.Fatal("failed to connect to MongoDB", zap.Error(err))
+	}
+	defer mg.Close(context.Background())
 
-	kq := queue.NewKafkaQueue(queue.KafkaConfig{
-		Brokers:      kafkaBrokers,
-		Topic:        kafkaTopic,
-		BatchSize:    100,
-		BatchTimeout: 1 * time.Second,
-	}, logger)
-	defer kq.Close()
+	// Initialize the crawl-job scheduler backend. QUEUE_BACKEND picks
+	// between Kafka (the default, for production), Redis (a lighter-weight
+	// priority queue for smaller deployments), and an in-process memory
+	// backend (for local runs and tests).
+	sched, err := newScheduler(getEnv("QUEUE_BACKEND", "kafka"), logger)
+	if err != nil {
+		logger.Fatal("failed to create scheduler", zap.Error(err))
+	}
+	defer sched.Close()
 
 	// Initialize crawler (for sitemap discovery)
 	crawlerConfig := crawler.CrawlerConfig{
@@ -69,12 +81,15 @@ func main() {
 	}
 
 	c := crawler.NewCrawler(crawlerConfig, logger)
+	defer c.Close()
 
 	service := &SchedulerService{
-		crawler:    c,
-		postgres:   pg,
-		kafkaQueue: kq,
-		logger:     logger,
+		crawler:     c,
+		postgres:    pg,
+		mongo:       mg,
+		scheduler:   sched,
+		authLimiter: auth.NewLimiter(),
+		logger:      logger,
 	}
 
 	// Start HTTP server
@@ -105,13 +120,17 @@ func (s *SchedulerService) startHTTPServer() {
 	})
 
 	// Schedule domain crawl
-	router.POST("/schedule/domain", s.handleScheduleDomain)
+	router.POST("/schedule/domain", s.requireScope(auth.ScopeScheduleDomain), s.handleScheduleDomain)
 
 	// Schedule sitemap crawl
-	router.POST("/schedule/sitemap", s.handleScheduleSitemap)
+	router.POST("/schedule/sitemap", s.requireScope(auth.ScopeScheduleDomain), s.handleScheduleSitemap)
 
 	// Bulk schedule from file
-	router.POST("/schedule/bulk", s.handleScheduleBulk)
+	router.POST("/schedule/bulk", s.requireScope(auth.ScopeScheduleBulk), s.handleScheduleBulk)
+
+	// Inspect or override a URL's adaptive re-crawl cadence
+	router.GET("/schedule/policy/*url", s.requireScope(auth.ScopeStatsRead), s.handleGetPolicy)
+	router.PUT("/schedule/policy/*url", s.requireScope(auth.ScopeScheduleDomain), s.handlePutPolicy)
 
 	port := getEnv("PORT", "8081")
 	s.logger.Info("starting HTTP server", zap.String("port", port))
@@ -121,6 +140,12 @@ func (s *SchedulerService) startHTTPServer() {
 	}
 }
 
+// requireScope wraps auth.Middleware with this service's Postgres-backed
+// key store and shared per-key rate limiter.
+func (s *SchedulerService) requireScope(scope string) gin.HandlerFunc {
+	return auth.Middleware(s.postgres, s.authLimiter, s.logger, scope)
+}
+
 func (s *SchedulerService) handleScheduleDomain(c *gin.Context) {
 	var req struct {
 		Domain   string `json:"domain" binding:"required"`
@@ -253,6 +278,11 @@ func (s *SchedulerService) handleScheduleBulk(c *gin.Context) {
 }
 
 func (s *SchedulerService) scheduleURLCrawl(ctx context.Context, urlStr string, maxDepth, priority int) (int64, error) {
+	urlStr, err := s.crawler.URLNormalizer().Normalize(urlStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid URL: %w", err)
+	}
+
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
 		return 0, fmt.Errorf("invalid URL: %w", err)
@@ -282,7 +312,7 @@ func (s *SchedulerService) scheduleURLCrawl(ctx context.Context, urlStr string,
 		Priority: priority,
 	}
 
-	if err := s.kafkaQueue.PublishCrawlJob(ctx, msg); err != nil {
+	if err := s.scheduler.Enqueue(ctx, msg); err != nil {
 		return 0, fmt.Errorf("failed to publish crawl job: %w", err)
 	}
 
@@ -326,13 +356,19 @@ func (s *SchedulerService) scheduleSitemapCrawl(ctx context.Context, sitemapURL
 	// Create messages for all URLs
 	var messages []*queue.CrawlMessage
 	for _, sitemapURL := range urls {
+		loc, err := s.crawler.URLNormalizer().Normalize(sitemapURL.Loc)
+		if err != nil {
+			s.logger.Warn("skipping invalid sitemap URL", zap.String("url", sitemapURL.Loc), zap.Error(err))
+			continue
+		}
+
 		messages = append(messages, &queue.CrawlMessage{
-			URL:      sitemapURL.Loc,
+			URL:      loc,
 			Depth:    0,
 			MaxDepth: maxDepth,
 			Priority: priority,
 			Metadata: map[string]string{
-				"sitemap":        sitemapURL,
+				"sitemap":       sitemapURL.Loc,
 				"parent_job_id": fmt.Sprintf("%d", parentJobID),
 			},
 		})
@@ -347,7 +383,7 @@ func (s *SchedulerService) scheduleSitemapCrawl(ctx context.Context, sitemapURL
 		}
 
 		batch := messages[i:end]
-		if err := s.kafkaQueue.PublishCrawlJobBatch(ctx, batch); err != nil {
+		if err := s.scheduler.EnqueueBatch(ctx, batch); err != nil {
 			s.logger.Error("failed to publish batch",
 				zap.Error(err),
 				zap.Int("batch", i/batchSize),
@@ -365,8 +401,16 @@ func (s *SchedulerService) scheduleSitemapCrawl(ctx context.Context, sitemapURL
 	return parentJobID, nil
 }
 
+// recrawlTickInterval is how often runScheduler recomputes cadences and
+// dispatches due re-crawls.
+const recrawlTickInterval = 1 * time.Hour
+
+// recrawlDueBatchSize bounds how many due URLs a single tick enqueues, so
+// one slow tick can't flood the queue backend.
+const recrawlDueBatchSize = 500
+
 func (s *SchedulerService) runScheduler(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Hour)
+	ticker := time.NewTicker(recrawlTickInterval)
 	defer ticker.Stop()
 
 	s.logger.Info("started periodic scheduler")
@@ -377,10 +421,190 @@ func (s *SchedulerService) runScheduler(ctx context.Context) {
 			s.logger.Info("stopping scheduler")
 			return
 		case <-ticker.C:
-			s.logger.Info("running periodic tasks")
-			// Add periodic tasks here (e.g., re-crawl old pages)
+			s.reconcileSchedules(ctx)
+			s.dispatchDueCrawls(ctx)
+		}
+	}
+}
+
+// reconcileSchedules recomputes the adaptive re-crawl cadence (see
+// internal/recrawl) for every tracked URL, from its most recent two
+// versions in MongoStorage and its previous cadence in PostgreSQL.
+func (s *SchedulerService) reconcileSchedules(ctx context.Context) {
+	urls, err := s.postgres.ListTrackedURLs(ctx)
+	if err != nil {
+		s.logger.Error("failed to list tracked urls", zap.Error(err))
+		return
+	}
+
+	for _, u := range urls {
+		if err := s.reconcileURLSchedule(ctx, u); err != nil {
+			s.logger.Error("failed to reconcile re-crawl schedule", zap.String("url", u), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("reconciled re-crawl schedules", zap.Int("urls", len(urls)))
+}
+
+func (s *SchedulerService) reconcileURLSchedule(ctx context.Context, url string) error {
+	versions, err := s.mongo.GetPageVersions(ctx, url, 2)
+	if err != nil {
+		return fmt.Errorf("failed to get page versions: %w", err)
+	}
+	if len(versions) < 2 {
+		// Not enough history yet to tell whether the content changed.
+		return nil
+	}
+
+	changed := versions[0].ContentHash != versions[1].ContentHash
+
+	prev, err := s.postgres.GetLatestCrawlJobByURL(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to get crawl history: %w", err)
+	}
+
+	policy := recrawl.Next(prev.IntervalSeconds, prev.ChangeScore, changed, time.Now())
+
+	if err := s.postgres.UpdateCrawlSchedule(ctx, url, policy); err != nil {
+		return fmt.Errorf("failed to update crawl schedule: %w", err)
+	}
+
+	return nil
+}
+
+// dispatchDueCrawls enqueues every URL whose reconciled cadence has come
+// due, highest priority first.
+func (s *SchedulerService) dispatchDueCrawls(ctx context.Context) {
+	due, err := s.postgres.GetDueCrawlJobs(ctx, time.Now(), recrawlDueBatchSize)
+	if err != nil {
+		s.logger.Error("failed to get due crawl jobs", zap.Error(err))
+		return
+	}
+
+	for _, job := range due {
+		msg := &queue.CrawlMessage{
+			JobID:    job.ID,
+			URL:      job.URL,
+			MaxDepth: job.MaxDepth,
+			Priority: job.Priority,
+		}
+
+		if err := s.scheduler.Enqueue(ctx, msg); err != nil {
+			s.logger.Error("failed to enqueue due re-crawl", zap.String("url", job.URL), zap.Error(err))
+			continue
 		}
 	}
+
+	s.logger.Info("dispatched due re-crawls", zap.Int("count", len(due)))
+}
+
+// policyResponse is the wire shape for GET/PUT /schedule/policy/:url.
+type policyResponse struct {
+	URL             string     `json:"url"`
+	NextCrawlAt     *time.Time `json:"next_crawl_at"`
+	IntervalSeconds int64      `json:"interval_seconds"`
+	ChangeScore     float64    `json:"change_score"`
+}
+
+func (s *SchedulerService) handleGetPolicy(c *gin.Context) {
+	target := strings.TrimPrefix(c.Param("url"), "/")
+	if target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	job, err := s.postgres.GetLatestCrawlJobByURL(c.Request.Context(), target)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no crawl history for url"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policyResponse{
+		URL:             target,
+		NextCrawlAt:     job.NextCrawlAt,
+		IntervalSeconds: job.IntervalSeconds,
+		ChangeScore:     job.ChangeScore,
+	})
+}
+
+func (s *SchedulerService) handlePutPolicy(c *gin.Context) {
+	target := strings.TrimPrefix(c.Param("url"), "/")
+	if target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	var req struct {
+		NextCrawlAt     *time.Time `json:"next_crawl_at"`
+		IntervalSeconds *int64     `json:"interval_seconds"`
+		ChangeScore     *float64   `json:"change_score"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := s.postgres.GetLatestCrawlJobByURL(c.Request.Context(), target)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no crawl history for url"})
+		return
+	}
+
+	policy := recrawl.Policy{
+		IntervalSeconds: job.IntervalSeconds,
+		ChangeScore:     job.ChangeScore,
+	}
+	if job.NextCrawlAt != nil {
+		policy.NextCrawlAt = *job.NextCrawlAt
+	}
+	if req.NextCrawlAt != nil {
+		policy.NextCrawlAt = *req.NextCrawlAt
+	}
+	if req.IntervalSeconds != nil {
+		policy.IntervalSeconds = *req.IntervalSeconds
+	}
+	if req.ChangeScore != nil {
+		policy.ChangeScore = *req.ChangeScore
+	}
+
+	if err := s.postgres.UpdateCrawlSchedule(c.Request.Context(), target, policy); err != nil {
+		s.logger.Error("failed to override crawl schedule", zap.String("url", target), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policyResponse{
+		URL:             target,
+		NextCrawlAt:     &policy.NextCrawlAt,
+		IntervalSeconds: policy.IntervalSeconds,
+		ChangeScore:     policy.ChangeScore,
+	})
+}
+
+// newScheduler builds the queue.Scheduler named by backend ("kafka",
+// "redis", or "memory"), wiring it from the matching env vars.
+func newScheduler(backend string, logger *zap.Logger) (queue.Scheduler, error) {
+	switch backend {
+	case "redis":
+		redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+		return queue.NewRedisScheduler(queue.RedisConfig{
+			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       redisDB,
+		}, logger), nil
+	case "memory":
+		return queue.NewMemoryScheduler(), nil
+	default:
+		kafkaBrokers := strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ",")
+		kafkaTopic := getEnv("KAFKA_TOPIC", "crawl-jobs")
+
+		return queue.NewKafkaScheduler(queue.KafkaConfig{
+			Brokers:      kafkaBrokers,
+			Topic:        kafkaTopic,
+			BatchSize:    100,
+			BatchTimeout: 1 * time.Second,
+		}, logger)
+	}
 }
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,183 @@
+// Package adminapi exposes a small HTTP surface for mutating live crawler
+// state without a redeploy — invalidating a cached robots.txt, nudging a
+// host's crawl rate, pausing/resuming/cancelling a job, or hot-swapping the
+// shared HTTPClient's timeout/retry/user-agent settings. It's the crawler's
+// analogue of Ethereum's admin_* RPC namespace: an operator-only surface,
+// never reachable by the public-facing crawl API.
+package adminapi
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/seo-platform/crawler/internal/crawler"
+	"github.com/seo-platform/crawler/internal/storage"
+)
+
+// Server holds the dependencies the admin handlers mutate.
+type Server struct {
+	crawler  *crawler.Crawler
+	postgres *storage.PostgresStorage
+	logger   *zap.Logger
+	secret   string
+}
+
+// NewServer creates an admin API server. secret is the shared value every
+// request must present via the X-Admin-Secret header; it should come from
+// an env var set only in the operator's environment, never committed.
+func NewServer(c *crawler.Crawler, postgres *storage.PostgresStorage, logger *zap.Logger, secret string) *Server {
+	return &Server{
+		crawler:  c,
+		postgres: postgres,
+		logger:   logger,
+		secret:   secret,
+	}
+}
+
+// RegisterRoutes mounts the admin endpoints under "/admin" on router, behind
+// the shared-secret middleware.
+func (s *Server) RegisterRoutes(router gin.IRouter) {
+	admin := router.Group("/admin", s.requireSecret())
+
+	admin.POST("/robots/invalidate", s.handleInvalidateRobots)
+	admin.GET("/robots/*domain", s.handleGetRobots)
+	admin.POST("/hosts/:host/rate", s.handleSetHostRate)
+	admin.POST("/jobs/:id/pause", s.handleJobStatus("paused"))
+	admin.POST("/jobs/:id/resume", s.handleJobStatus("pending"))
+	admin.POST("/jobs/:id/cancel", s.handleJobStatus("cancelled"))
+	admin.POST("/client/config", s.handleUpdateClientConfig)
+}
+
+// requireSecret rejects any request whose X-Admin-Secret header doesn't
+// match s.secret, using a constant-time comparison so response timing can't
+// leak how much of the secret a guess got right.
+func (s *Server) requireSecret() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Secret")
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(s.secret)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin secret"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Server) handleInvalidateRobots(c *gin.Context) {
+	var req struct {
+		Domain string `json:"domain" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.crawler.RobotsCache().Invalidate(req.Domain)
+	s.logger.Info("admin: invalidated robots.txt cache entry", zap.String("domain", req.Domain))
+	c.JSON(http.StatusOK, gin.H{"status": "invalidated", "domain": req.Domain})
+}
+
+func (s *Server) handleGetRobots(c *gin.Context) {
+	domain := strings.TrimPrefix(c.Param("domain"), "/")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "domain is required"})
+		return
+	}
+
+	info, ok := s.crawler.RobotsCache().Inspect(domain)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no cached robots.txt entry for domain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"domain":       domain,
+		"has_robots":   info.HasRobots,
+		"agent_delays": info.AgentDelays,
+		"sitemaps":     info.Sitemaps,
+		"fetched_at":   info.FetchedAt,
+	})
+}
+
+func (s *Server) handleSetHostRate(c *gin.Context) {
+	host := c.Param("host")
+
+	var req struct {
+		Interval string `json:"interval" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid interval: " + err.Error()})
+		return
+	}
+
+	s.crawler.HostScheduler().SetHostRate(host, interval)
+	s.logger.Info("admin: overrode host crawl rate",
+		zap.String("host", host),
+		zap.Duration("interval", interval),
+	)
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "host": host, "interval": interval.String()})
+}
+
+// handleJobStatus returns a handler that sets a job's status to status,
+// shared by the pause/resume/cancel endpoints since they only differ in the
+// target status value.
+func (s *Server) handleJobStatus(status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+			return
+		}
+
+		if err := s.postgres.UpdateCrawlJobStatus(c.Request.Context(), id, status, nil); err != nil {
+			s.logger.Error("admin: failed to update job status",
+				zap.Int64("job_id", id),
+				zap.String("status", status),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update job status"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"job_id": id, "status": status})
+	}
+}
+
+func (s *Server) handleUpdateClientConfig(c *gin.Context) {
+	var req struct {
+		TimeoutSeconds int    `json:"timeout_seconds"`
+		MaxRetries     int    `json:"max_retries"`
+		UserAgent      string `json:"user_agent"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	update := crawler.ClientConfigUpdate{
+		MaxRetries: req.MaxRetries,
+		UserAgent:  req.UserAgent,
+	}
+	if req.TimeoutSeconds > 0 {
+		update.Timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+
+	s.crawler.HTTPClient().UpdateConfig(update)
+	s.logger.Info("admin: updated HTTP client config",
+		zap.Int("timeout_seconds", req.TimeoutSeconds),
+		zap.Int("max_retries", req.MaxRetries),
+		zap.String("user_agent", req.UserAgent),
+	)
+	c.JSON(http.StatusOK, gin.H{"status": "updated"})
+}
@@ -0,0 +1,81 @@
+// Package auth implements API-key authentication and per-key rate limiting
+// for the scheduler's HTTP API: hashed keys persisted in Postgres, a Gin
+// middleware that validates the Authorization header and enforces scopes,
+// and a token-bucket limiter keyed by API key rather than by domain.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Scope names enforced per-route by Middleware.
+const (
+	ScopeScheduleDomain = "schedule:domain"
+	ScopeScheduleBulk   = "schedule:bulk"
+	ScopeStatsRead      = "stats:read"
+)
+
+// APIKey is one issued credential, as persisted in Postgres's api_keys
+// table. Hash is the SHA-256 of the raw token, hex-encoded; the raw token
+// itself is never stored, only shown to the operator once at mint time.
+type APIKey struct {
+	ID              int64
+	Hash            string
+	Name            string
+	Scopes          []string
+	RateLimitPerMin int
+	CreatedAt       time.Time
+	RevokedAt       *time.Time
+}
+
+// HasScope reports whether k carries scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoked reports whether k has been revoked.
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Store is the persistence boundary Middleware and cmd/crawlerctl depend
+// on; storage.PostgresStorage satisfies it.
+type Store interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	ListAPIKeys(ctx context.Context) ([]*APIKey, error)
+}
+
+// tokenBytes is the amount of randomness in a minted token; 32 bytes of
+// crypto/rand output hex-encodes to a 64-character bearer token.
+const tokenBytes = 32
+
+// GenerateToken mints a new random bearer token and returns it alongside
+// its hash: the hash is what gets persisted via CreateAPIKey, the token is
+// shown to the operator exactly once and never stored.
+func GenerateToken() (token, hash string, err error) {
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token = hex.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -5,10 +5,16 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/seo-platform/crawler/internal/stats"
 )
 
 // Crawler represents the main crawler engine
@@ -17,20 +23,49 @@ type Crawler struct {
 	rateLimiter *DomainRateLimiter
 	robotsCache *RobotsCache
 	sitemap     *SitemapParser
-	logger      *zap.Logger
-	config      CrawlerConfig
+	feed        *FeedParser
+	// hostScheduler provides a per-host token bucket seeded from robots.txt
+	// Crawl-delay, for callers that fetch directly rather than through
+	// CrawlerService's queue (where politeness.Controller is primary).
+	hostScheduler *HostScheduler
+	// urlNormalizer canonicalizes URLs before they're used as cache/storage
+	// keys, so RobotsCache, PostgresStorage, and the crawl frontier all
+	// recognize the same page regardless of how a URL was spelled.
+	urlNormalizer *URLNormalizer
+	logger        *zap.Logger
+	config        CrawlerConfig
+	onionDomains  map[string]bool
+	onionMu       sync.Mutex
+	// collector is nil unless SetCollector was called, in which case every
+	// Crawl records its outcome against it.
+	collector *stats.Collector
 }
 
 // CrawlerConfig holds crawler configuration
 type CrawlerConfig struct {
-	UserAgent        string
-	RespectRobots    bool
-	MaxDepth         int
-	MaxConcurrency   int
-	RequestTimeout   time.Duration
-	RateLimitPerSec  float64
-	FollowRedirects  bool
+	UserAgent      string
+	RespectRobots  bool
+	MaxDepth       int
+	MaxConcurrency int
+	RequestTimeout time.Duration
+	// RateLimitPerSec is each host's starting rate on the DomainRateLimiter
+	// that paces every Crawl call. Observe adjusts it from there (up to 10x
+	// by default), and a robots.txt Crawl-delay or OnionRateLimitPerSec
+	// replaces it for the hosts they apply to. Sitemap and feed fetches are
+	// paced by HostScheduler instead and do not use this rate.
+	RateLimitPerSec   float64
+	FollowRedirects   bool
 	JavaScriptEnabled bool
+	// ProxyURL routes all requests through a socks5:// or socks5h:// proxy
+	// (typically a local Tor daemon), required to reach .onion hosts.
+	ProxyURL string
+	// AllowOnion permits the crawler to follow .onion links and, symmetrically,
+	// permits a .onion seed to follow clearnet links. Without it, crawls never
+	// cross the onion/clearnet boundary, even for related-resource fetches.
+	AllowOnion bool
+	// OnionRateLimitPerSec overrides RateLimitPerSec for .onion domains, since
+	// Tor circuits are much slower than a direct connection.
+	OnionRateLimitPerSec float64
 }
 
 // NewCrawler creates a new crawler instance
@@ -57,46 +92,60 @@ func NewCrawler(config CrawlerConfig, logger *zap.Logger) *Crawler {
 		RetryDelay:     1 * time.Second,
 		UserAgent:      config.UserAgent,
 		FollowRedirect: config.FollowRedirects,
+		ProxyURL:       config.ProxyURL,
 	}, logger)
 
 	rateLimiter := NewDomainRateLimiter(RateLimiterConfig{
-		DefaultRate:  float64(config.RateLimitPerSec),
+		DefaultRate:  rate.Limit(config.RateLimitPerSec),
 		DefaultBurst: 3,
 	})
 
 	robotsCache := NewRobotsCache(httpClient, logger, 24*time.Hour)
-	sitemapParser := NewSitemapParser(httpClient, logger)
+	hostScheduler := NewHostScheduler(httpClient, robotsCache, config.UserAgent)
+	sitemapParser := NewSitemapParser(hostScheduler, logger)
+	feedParser := NewFeedParser(hostScheduler, logger)
 
 	return &Crawler{
-		httpClient:  httpClient,
-		rateLimiter: rateLimiter,
-		robotsCache: robotsCache,
-		sitemap:     sitemapParser,
-		logger:      logger,
-		config:      config,
+		httpClient:    httpClient,
+		rateLimiter:   rateLimiter,
+		robotsCache:   robotsCache,
+		sitemap:       sitemapParser,
+		feed:          feedParser,
+		hostScheduler: hostScheduler,
+		urlNormalizer: NewURLNormalizer(),
+		logger:        logger,
+		config:        config,
+		onionDomains:  make(map[string]bool),
 	}
 }
 
 // CrawlResult represents the result of crawling a single URL
 type CrawlResult struct {
-	URL           string
-	FinalURL      string
-	StatusCode    int
-	ContentType   string
-	Content       []byte
-	ContentHash   string
-	Links         []string
-	Title         string
-	MetaTags      map[string]string
-	Headers       map[string][]string
-	CrawledAt     time.Time
-	Duration      time.Duration
-	Depth         int
-	Error         error
-}
-
-// Crawl fetches and processes a single URL
-func (c *Crawler) Crawl(ctx context.Context, targetURL string, depth int) (*CrawlResult, error) {
+	URL         string
+	FinalURL    string
+	StatusCode  int
+	ContentType string
+	Content     []byte
+	ContentHash string
+	Links       []string
+	Title       string
+	MetaTags    map[string]string
+	Headers     map[string][]string
+	CrawledAt   time.Time
+	Duration    time.Duration
+	Depth       int
+	Error       error
+	// RequestLine, RequestHeaders, and RemoteAddr let callers (e.g. the WARC
+	// writer) reconstruct request/response records.
+	RequestLine    string
+	RequestHeaders http.Header
+	RemoteAddr     string
+}
+
+// Crawl fetches and processes a single URL. hints is optional: if given, it
+// is forwarded to HTTPClient.Fetch so an unchanged page can be revalidated
+// with a conditional GET instead of re-downloaded.
+func (c *Crawler) Crawl(ctx context.Context, targetURL string, depth int, hints ...RevalidateHints) (*CrawlResult, error) {
 	startTime := time.Now()
 
 	c.logger.Info("crawling URL",
@@ -124,11 +173,13 @@ func (c *Crawler) Crawl(ctx context.Context, targetURL string, depth int) (*Craw
 			c.logger.Info("URL disallowed by robots.txt",
 				zap.String("url", targetURL),
 			)
-			return &CrawlResult{
+			result := &CrawlResult{
 				URL:       targetURL,
 				Error:     fmt.Errorf("disallowed by robots.txt"),
 				CrawledAt: time.Now(),
-			}, nil
+			}
+			c.recordOutcome(domain, result, time.Since(startTime))
+			return result, nil
 		}
 
 		// Get and apply crawl delay
@@ -138,20 +189,33 @@ func (c *Crawler) Crawl(ctx context.Context, targetURL string, depth int) (*Craw
 		}
 	}
 
-	// Apply rate limiting
+	// Tor circuits are much slower than a direct connection, so onion
+	// domains get their own (lower) rate the first time they're seen
+	if c.config.OnionRateLimitPerSec > 0 && isOnionHost(domain) {
+		c.ensureOnionRateLimit(domain)
+	}
+
+	// Apply rate limiting. rateLimiter is the single per-host pace-setter
+	// for this path: it already folds in robots.txt Crawl-delay (above) and
+	// AIMD-adjusts itself off each fetch's outcome below, so Crawl fetches
+	// through httpClient directly rather than also queuing behind
+	// hostScheduler's own token bucket, which would just make every page
+	// wait twice for the same host.
 	if err := c.rateLimiter.Wait(ctx, domain); err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
-	// Fetch the page
-	resp, err := c.httpClient.Fetch(ctx, targetURL)
+	resp, err := c.httpClient.Fetch(ctx, targetURL, hints...)
+	c.rateLimiter.Observe(domain, resp, err)
 	if err != nil {
-		return &CrawlResult{
+		result := &CrawlResult{
 			URL:       targetURL,
 			Error:     err,
 			CrawledAt: time.Now(),
 			Duration:  time.Since(startTime),
-		}, err
+		}
+		c.recordOutcome(domain, result, result.Duration)
+		return result, err
 	}
 
 	// Calculate content hash
@@ -164,16 +228,19 @@ func (c *Crawler) Crawl(ctx context.Context, targetURL string, depth int) (*Craw
 	}
 
 	result := &CrawlResult{
-		URL:         targetURL,
-		FinalURL:    resp.FinalURL,
-		StatusCode:  resp.StatusCode,
-		ContentType: resp.ContentType,
-		Content:     resp.Body,
-		ContentHash: contentHash,
-		Headers:     headers,
-		CrawledAt:   time.Now(),
-		Duration:    resp.Duration,
-		Depth:       depth,
+		URL:            targetURL,
+		FinalURL:       resp.FinalURL,
+		StatusCode:     resp.StatusCode,
+		ContentType:    resp.ContentType,
+		Content:        resp.Body,
+		ContentHash:    contentHash,
+		Headers:        headers,
+		CrawledAt:      time.Now(),
+		Duration:       resp.Duration,
+		Depth:          depth,
+		RequestLine:    resp.RequestLine,
+		RequestHeaders: resp.RequestHeaders,
+		RemoteAddr:     resp.RemoteAddr,
 	}
 
 	c.logger.Info("crawl completed",
@@ -183,6 +250,7 @@ func (c *Crawler) Crawl(ctx context.Context, targetURL string, depth int) (*Craw
 		zap.Duration("duration", resp.Duration),
 	)
 
+	c.recordOutcome(domain, result, resp.Duration)
 	return result, nil
 }
 
@@ -226,6 +294,96 @@ func (c *Crawler) ParseSitemap(ctx context.Context, sitemapURL string) ([]Sitema
 	return c.sitemap.ParseRecursive(ctx, sitemapURL, 3)
 }
 
+// ParseFeed parses an RSS, Atom, or JSON Feed and returns the normalized result
+func (c *Crawler) ParseFeed(ctx context.Context, feedURL string) (*FeedResult, error) {
+	return c.feed.Parse(ctx, feedURL)
+}
+
+// ensureOnionRateLimit sets a domain's rate limiter to OnionRateLimitPerSec
+// the first time it's seen; it must not run on every request, since that
+// would replace the limiter (and its accumulated burst tokens) each time
+func (c *Crawler) ensureOnionRateLimit(domain string) {
+	c.onionMu.Lock()
+	defer c.onionMu.Unlock()
+
+	if c.onionDomains[domain] {
+		return
+	}
+	c.onionDomains[domain] = true
+	c.rateLimiter.SetRate(domain, rate.Limit(c.config.OnionRateLimitPerSec), 1)
+}
+
+// isOnionHost reports whether host is a Tor hidden-service address
+func isOnionHost(host string) bool {
+	return strings.HasSuffix(strings.ToLower(host), ".onion")
+}
+
+// AllowOnion reports whether the crawler may cross the onion/clearnet
+// boundary: follow .onion links from a clearnet seed, or clearnet links
+// from a .onion seed
+func (c *Crawler) AllowOnion() bool {
+	return c.config.AllowOnion
+}
+
+// SetCollector attaches a stats.Collector that every subsequent Crawl call
+// records its outcome against. Passing nil disables recording.
+func (c *Crawler) SetCollector(collector *stats.Collector) {
+	c.collector = collector
+}
+
+// recordOutcome tallies a fetch's outcome against c.collector, if one is
+// attached. domain is passed separately from result since failed URL
+// parses never produce a CrawlResult.
+func (c *Crawler) recordOutcome(domain string, result *CrawlResult, duration time.Duration) {
+	if c.collector == nil {
+		return
+	}
+
+	outcome := stats.ClassifyOutcome(result.StatusCode, result.Error)
+	if result.Error != nil && result.Error.Error() == "disallowed by robots.txt" {
+		outcome = stats.OutcomeRobotsDenied
+	}
+	c.collector.Record(domain, outcome, int64(len(result.Content)), duration)
+}
+
+// HTTPClient returns the crawler's underlying HTTP client, so other
+// subsystems (e.g. the snapshot archiver) can reuse its retry/timeout config
+func (c *Crawler) HTTPClient() *HTTPClient {
+	return c.httpClient
+}
+
+// RobotsCache returns the crawler's robots.txt cache
+func (c *Crawler) RobotsCache() *RobotsCache {
+	return c.robotsCache
+}
+
+// RateLimiter returns the crawler's domain rate limiter
+func (c *Crawler) RateLimiter() *DomainRateLimiter {
+	return c.rateLimiter
+}
+
+// HostScheduler returns the crawler's per-host token-bucket scheduler
+func (c *Crawler) HostScheduler() *HostScheduler {
+	return c.hostScheduler
+}
+
+// UserAgent returns the configured crawler user agent
+func (c *Crawler) UserAgent() string {
+	return c.config.UserAgent
+}
+
+// URLNormalizer returns the crawler's shared URL canonicalizer
+func (c *Crawler) URLNormalizer() *URLNormalizer {
+	return c.urlNormalizer
+}
+
+// Close releases background resources the crawler started (currently just
+// the robots.txt cache's cleanup goroutine). Safe to call once during
+// shutdown, alongside the other defer X.Close() calls in main.
+func (c *Crawler) Close() {
+	c.robotsCache.Close()
+}
+
 // calculateHash calculates SHA256 hash of content
 func calculateHash(content []byte) string {
 	hash := sha256.Sum256(content)
@@ -236,5 +394,5 @@ func calculateHash(content []byte) string {
 func IsHTMLContent(contentType string) bool {
 	return contentType != "" &&
 		(contentType == "text/html" ||
-		 contentType[:9] == "text/html")
+			contentType[:9] == "text/html")
 }
@@ -0,0 +1,369 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/seo-platform/crawler/internal/dateparser"
+	"go.uber.org/zap"
+)
+
+// FeedParser parses RSS 2.0, Atom 1.0, and JSON Feed 1.1 feeds
+type FeedParser struct {
+	client Fetcher
+	logger *zap.Logger
+}
+
+// NewFeedParser creates a new feed parser
+func NewFeedParser(client Fetcher, logger *zap.Logger) *FeedParser {
+	return &FeedParser{
+		client: client,
+		logger: logger,
+	}
+}
+
+// FeedResult contains the normalized result of parsing a feed
+type FeedResult struct {
+	Title string
+	Link  string
+	Items []FeedItem
+}
+
+// FeedItem represents a single normalized entry from any feed format
+type FeedItem struct {
+	URL         string
+	Title       string
+	Published   string
+	PublishedAt time.Time
+	Updated     string
+	UpdatedAt   time.Time
+	Author      string
+	Summary     string
+	Content     string
+	Media       []FeedMedia
+}
+
+// FeedMedia represents an enclosure or attachment attached to a feed item
+type FeedMedia struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// rssFeed represents an RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name  `xml:"rss"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title string    `xml:"title"`
+	Link  string    `xml:"link"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title      string         `xml:"title"`
+	Link       string         `xml:"link"`
+	PubDate    string         `xml:"pubDate"`
+	Author     string         `xml:"author"`
+	Creator    string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Summary    string         `xml:"description"`
+	Content    string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Enclosures []rssEnclosure `xml:"enclosure"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+// atomFeed represents an Atom 1.0 document
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"http://www.w3.org/2005/Atom title"`
+	Links   []atomLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Entries []atomEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"http://www.w3.org/2005/Atom title"`
+	Links     []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+	Published string     `xml:"http://www.w3.org/2005/Atom published"`
+	Updated   string     `xml:"http://www.w3.org/2005/Atom updated"`
+	Author    atomAuthor `xml:"http://www.w3.org/2005/Atom author"`
+	Summary   string     `xml:"http://www.w3.org/2005/Atom summary"`
+	Content   string     `xml:"http://www.w3.org/2005/Atom content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"http://www.w3.org/2005/Atom name"`
+}
+
+// jsonFeed represents a JSON Feed 1.1 document
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	Summary       string               `json:"summary"`
+	ContentText   string               `json:"content_text"`
+	ContentHTML   string               `json:"content_html"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        jsonFeedAuthor       `json:"author"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size_in_bytes"`
+}
+
+// Parse fetches a feed URL and normalizes it into a FeedResult
+func (fp *FeedParser) Parse(ctx context.Context, feedURL string) (*FeedResult, error) {
+	fp.logger.Info("parsing feed", zap.String("url", feedURL))
+
+	resp, err := fp.client.Fetch(ctx, feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var reader io.Reader = strings.NewReader(string(resp.Body))
+	if strings.HasSuffix(feedURL, ".gz") || resp.Headers.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(strings.NewReader(string(resp.Body)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed content: %w", err)
+	}
+
+	result, err := fp.parseContent(content, resp.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	fp.logger.Info("parsed feed",
+		zap.String("url", feedURL),
+		zap.String("title", result.Title),
+		zap.Int("items", len(result.Items)),
+	)
+
+	return result, nil
+}
+
+// parseContent detects the feed format and decodes it
+func (fp *FeedParser) parseContent(content []byte, contentType string) (*FeedResult, error) {
+	switch fp.detectFormat(content, contentType) {
+	case "json":
+		return fp.parseJSONFeed(content)
+	case "atom":
+		return fp.parseAtomFeed(content)
+	default:
+		return fp.parseRSSFeed(content)
+	}
+}
+
+// detectFormat determines the feed format from content-type, falling back to content sniffing
+func (fp *FeedParser) detectFormat(content []byte, contentType string) string {
+	switch {
+	case strings.Contains(contentType, "application/feed+json"),
+		strings.Contains(contentType, "application/json"):
+		return "json"
+	case strings.Contains(contentType, "application/atom+xml"):
+		return "atom"
+	case strings.Contains(contentType, "application/rss+xml"):
+		return "rss"
+	}
+
+	trimmed := strings.TrimSpace(string(content))
+	if strings.HasPrefix(trimmed, "{") {
+		return "json"
+	}
+	if strings.Contains(trimmed[:min(len(trimmed), 512)], "<feed") {
+		return "atom"
+	}
+
+	return "rss"
+}
+
+// parseRSSFeed parses an RSS 2.0 document
+func (fp *FeedParser) parseRSSFeed(content []byte) (*FeedResult, error) {
+	var feed rssFeed
+	if err := xml.Unmarshal(content, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+	}
+
+	items := make([]FeedItem, 0, len(feed.Channel.Items))
+	for _, item := range feed.Channel.Items {
+		author := item.Author
+		if author == "" {
+			author = item.Creator
+		}
+
+		content := item.Content
+		if content == "" {
+			content = item.Summary
+		}
+
+		media := make([]FeedMedia, 0, len(item.Enclosures))
+		for _, enc := range item.Enclosures {
+			media = append(media, FeedMedia{URL: enc.URL, Type: enc.Type, Length: enc.Length})
+		}
+
+		items = append(items, FeedItem{
+			URL:         item.Link,
+			Title:       item.Title,
+			Published:   item.PubDate,
+			PublishedAt: fp.parseDate(item.PubDate),
+			Author:      author,
+			Summary:     item.Summary,
+			Content:     content,
+			Media:       media,
+		})
+	}
+
+	return &FeedResult{
+		Title: feed.Channel.Title,
+		Link:  feed.Channel.Link,
+		Items: items,
+	}, nil
+}
+
+// parseAtomFeed parses an Atom 1.0 document
+func (fp *FeedParser) parseAtomFeed(content []byte) (*FeedResult, error) {
+	var feed atomFeed
+	if err := xml.Unmarshal(content, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+	}
+
+	items := make([]FeedItem, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		items = append(items, FeedItem{
+			URL:         atomEntryLink(entry.Links),
+			Title:       entry.Title,
+			Published:   entry.Published,
+			PublishedAt: fp.parseDate(entry.Published),
+			Updated:     entry.Updated,
+			UpdatedAt:   fp.parseDate(entry.Updated),
+			Author:      entry.Author.Name,
+			Summary:     entry.Summary,
+			Content:     entry.Content,
+		})
+	}
+
+	return &FeedResult{
+		Title: feed.Title,
+		Link:  atomEntryLink(feed.Links),
+		Items: items,
+	}, nil
+}
+
+// atomEntryLink picks the alternate (or first) link from a set of Atom links
+func atomEntryLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// parseJSONFeed parses a JSON Feed 1.1 document
+func (fp *FeedParser) parseJSONFeed(content []byte) (*FeedResult, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal(content, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON feed: %w", err)
+	}
+
+	items := make([]FeedItem, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		media := make([]FeedMedia, 0, len(item.Attachments))
+		for _, att := range item.Attachments {
+			media = append(media, FeedMedia{URL: att.URL, Type: att.MimeType, Length: att.Size})
+		}
+
+		items = append(items, FeedItem{
+			URL:         item.URL,
+			Title:       item.Title,
+			Published:   item.DatePublished,
+			PublishedAt: fp.parseDate(item.DatePublished),
+			Updated:     item.DateModified,
+			UpdatedAt:   fp.parseDate(item.DateModified),
+			Author:      item.Author.Name,
+			Summary:     item.Summary,
+			Content:     content,
+			Media:       media,
+		})
+	}
+
+	return &FeedResult{
+		Title: feed.Title,
+		Link:  feed.HomePageURL,
+		Items: items,
+	}, nil
+}
+
+// parseDate parses a feed date field, logging and returning the zero value
+// on failure rather than aborting the whole feed
+func (fp *FeedParser) parseDate(raw string) time.Time {
+	if raw == "" {
+		return time.Time{}
+	}
+
+	t, err := dateparser.Parse(raw)
+	if err != nil {
+		fp.logger.Warn("failed to parse feed date", zap.String("raw", raw), zap.Error(err))
+		return time.Time{}
+	}
+
+	return t
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
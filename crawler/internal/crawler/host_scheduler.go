@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// defaultHostInterval is the refill interval HostScheduler seeds for a host
+// it has no robots.txt Crawl-delay or override for yet.
+const defaultHostInterval = 1 * time.Second
+
+// Fetcher is satisfied by both *HTTPClient and *HostScheduler, so callers
+// that fetch many URLs from one host (the sitemap and feed parsers, which
+// NewCrawler hands a HostScheduler) can take either without depending on
+// the concrete client type. Crawl itself does not go through a Fetcher.
+type Fetcher interface {
+	Fetch(ctx context.Context, targetURL string, hints ...RevalidateHints) (*FetchResponse, error)
+}
+
+// HostScheduler wraps an HTTPClient with a per-host token bucket, so
+// concurrent fetches to the same host queue behind a single pacing limiter
+// instead of all firing at once. Each host's refill interval is seeded from
+// RobotsCache.GetCrawlDelay the first time that host is seen, and can be
+// overridden at runtime via SetHostRate (e.g. from an admin API).
+type HostScheduler struct {
+	client      *HTTPClient
+	robotsCache *RobotsCache
+	userAgent   string
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewHostScheduler creates a HostScheduler that paces every Fetch call
+// through client, consulting robotsCache for each host's Crawl-delay.
+func NewHostScheduler(client *HTTPClient, robotsCache *RobotsCache, userAgent string) *HostScheduler {
+	return &HostScheduler{
+		client:      client,
+		robotsCache: robotsCache,
+		userAgent:   userAgent,
+		limiters:    make(map[string]*rate.Limiter),
+	}
+}
+
+// Fetch waits for targetURL's host to clear its token bucket, then delegates
+// to the underlying HTTPClient.Fetch. It respects ctx.Done() during the wait.
+func (hs *HostScheduler) Fetch(ctx context.Context, targetURL string, hints ...RevalidateHints) (*FetchResponse, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	limiter := hs.getOrCreate(ctx, targetURL, parsed.Host)
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("host scheduler wait: %w", err)
+	}
+
+	return hs.client.Fetch(ctx, targetURL, hints...)
+}
+
+// SetHostRate overrides host's refill interval, e.g. for an admin API that
+// needs to slow down or speed up a specific host at runtime. interval is
+// clamped to maxCrawlDelay to guard against an accidental near-infinite stall.
+func (hs *HostScheduler) SetHostRate(host string, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHostInterval
+	}
+	if interval > maxCrawlDelay {
+		interval = maxCrawlDelay
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.limiters[host] = rate.NewLimiter(rate.Every(interval), 1)
+}
+
+// getOrCreate returns host's limiter, seeding it from robots.txt Crawl-delay
+// the first time host is seen.
+func (hs *HostScheduler) getOrCreate(ctx context.Context, targetURL, host string) *rate.Limiter {
+	hs.mu.Lock()
+	limiter, exists := hs.limiters[host]
+	hs.mu.Unlock()
+	if exists {
+		return limiter
+	}
+
+	interval := defaultHostInterval
+	if hs.robotsCache != nil {
+		if delay, err := hs.robotsCache.GetCrawlDelay(ctx, targetURL, hs.userAgent); err == nil && delay > 0 {
+			interval = delay
+		}
+	}
+	if interval > maxCrawlDelay {
+		interval = maxCrawlDelay
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	// Double-check after acquiring the write lock
+	if limiter, exists = hs.limiters[host]; exists {
+		return limiter
+	}
+
+	limiter = rate.NewLimiter(rate.Every(interval), 1)
+	hs.limiters[host] = limiter
+	return limiter
+}
@@ -6,26 +6,43 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/net/proxy"
 )
 
 // HTTPClient represents an HTTP client with retry and timeout logic
 type HTTPClient struct {
-	client *http.Client
+	// client is an atomic.Pointer rather than a plain *http.Client because
+	// UpdateConfig can hot-swap its Timeout while Fetch/Head are concurrently
+	// calling client.Do; mutating http.Client.Timeout in place under a
+	// different lock than the one Do's callers use would be a data race.
+	client atomic.Pointer[http.Client]
 	logger *zap.Logger
+	// mu guards config: an admin API can hot-swap Timeout/MaxRetries/
+	// UserAgent at runtime, so every read goes through getConfig rather than
+	// touching the field directly.
+	mu     sync.RWMutex
 	config ClientConfig
 }
 
 // ClientConfig holds configuration for the HTTP client
 type ClientConfig struct {
-	Timeout       time.Duration
-	MaxRetries    int
-	RetryDelay    time.Duration
-	UserAgent     string
+	Timeout        time.Duration
+	MaxRetries     int
+	RetryDelay     time.Duration
+	UserAgent      string
 	FollowRedirect bool
-	MaxRedirects  int
+	MaxRedirects   int
+	// ProxyURL, when set, routes all requests through it. A socks5:// or
+	// socks5h:// URL (e.g. "socks5h://127.0.0.1:9050" for a local Tor
+	// daemon) is required to reach .onion hosts.
+	ProxyURL string
 }
 
 // NewHTTPClient creates a new HTTP client with custom configuration
@@ -56,6 +73,17 @@ func NewHTTPClient(config ClientConfig, logger *zap.Logger) *HTTPClient {
 		DisableCompression: false,
 	}
 
+	if config.ProxyURL != "" {
+		if dialer, err := newProxyDialer(config.ProxyURL); err != nil {
+			logger.Error("invalid proxy URL, falling back to a direct connection",
+				zap.String("proxy_url", config.ProxyURL),
+				zap.Error(err),
+			)
+		} else {
+			transport.DialContext = dialer.DialContext
+		}
+	}
+
 	client := &http.Client{
 		Timeout:   config.Timeout,
 		Transport: transport,
@@ -74,11 +102,12 @@ func NewHTTPClient(config ClientConfig, logger *zap.Logger) *HTTPClient {
 		}
 	}
 
-	return &HTTPClient{
-		client: client,
+	c := &HTTPClient{
 		logger: logger,
 		config: config,
 	}
+	c.client.Store(client)
+	return c
 }
 
 // FetchResponse represents the response from a fetch operation
@@ -90,17 +119,33 @@ type FetchResponse struct {
 	ContentType string
 	FinalURL    string
 	Duration    time.Duration
+	// RequestLine, RequestHeaders, and RemoteAddr let callers (e.g. the WARC
+	// writer) reconstruct request/response records.
+	RequestLine    string
+	RequestHeaders http.Header
+	RemoteAddr     string
 }
 
-// Fetch performs an HTTP GET request with retry logic
-func (c *HTTPClient) Fetch(ctx context.Context, url string) (*FetchResponse, error) {
+// RevalidateHints carries the validators from a previous crawl of a URL, so
+// Fetch can issue a conditional request and let the server answer with a
+// bandwidth-free 304 Not Modified instead of resending the full body.
+type RevalidateHints struct {
+	ETag         string
+	LastModified string
+}
+
+// Fetch performs an HTTP GET request with retry logic. hints is optional: if
+// given, its ETag and LastModified populate If-None-Match and
+// If-Modified-Since on the request.
+func (c *HTTPClient) Fetch(ctx context.Context, url string, hints ...RevalidateHints) (*FetchResponse, error) {
 	var lastErr error
 	startTime := time.Now()
+	cfg := c.getConfig()
 
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff
-			delay := c.config.RetryDelay * time.Duration(1<<uint(attempt-1))
+			delay := cfg.RetryDelay * time.Duration(1<<uint(attempt-1))
 			c.logger.Info("retrying request",
 				zap.String("url", url),
 				zap.Int("attempt", attempt),
@@ -118,13 +163,31 @@ func (c *HTTPClient) Fetch(ctx context.Context, url string) (*FetchResponse, err
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		req.Header.Set("User-Agent", c.config.UserAgent)
+		req.Header.Set("User-Agent", cfg.UserAgent)
 		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 		req.Header.Set("Accept-Encoding", "gzip, deflate")
 		req.Header.Set("Connection", "keep-alive")
 
-		resp, err := c.client.Do(req)
+		if len(hints) > 0 {
+			if hints[0].ETag != "" {
+				req.Header.Set("If-None-Match", hints[0].ETag)
+			}
+			if hints[0].LastModified != "" {
+				req.Header.Set("If-Modified-Since", hints[0].LastModified)
+			}
+		}
+
+		// Capture the remote IP actually dialed, for WARC-IP-Address
+		var remoteAddr string
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				remoteAddr = info.Conn.RemoteAddr().String()
+			},
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+		resp, err := c.client.Load().Do(req)
 		if err != nil {
 			lastErr = err
 			c.logger.Warn("request failed",
@@ -137,19 +200,23 @@ func (c *HTTPClient) Fetch(ctx context.Context, url string) (*FetchResponse, err
 
 		defer resp.Body.Close()
 
-		// Read response body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = err
-			c.logger.Warn("failed to read response body",
-				zap.String("url", url),
-				zap.Error(err),
-			)
-			continue
+		// A 304 carries no body; reading it would just consume an empty
+		// stream, so skip straight to building the response.
+		var body []byte
+		if resp.StatusCode != http.StatusNotModified {
+			body, err = io.ReadAll(resp.Body)
+			if err != nil {
+				lastErr = err
+				c.logger.Warn("failed to read response body",
+					zap.String("url", url),
+					zap.Error(err),
+				)
+				continue
+			}
 		}
 
 		// Check if we should retry based on status code
-		if c.shouldRetry(resp.StatusCode) && attempt < c.config.MaxRetries {
+		if c.shouldRetry(resp.StatusCode) && attempt < cfg.MaxRetries {
 			lastErr = fmt.Errorf("received status code %d", resp.StatusCode)
 			c.logger.Warn("received retriable status code",
 				zap.String("url", url),
@@ -161,13 +228,16 @@ func (c *HTTPClient) Fetch(ctx context.Context, url string) (*FetchResponse, err
 		duration := time.Since(startTime)
 
 		response := &FetchResponse{
-			StatusCode:  resp.StatusCode,
-			Body:        body,
-			Headers:     resp.Header,
-			URL:         url,
-			ContentType: resp.Header.Get("Content-Type"),
-			FinalURL:    resp.Request.URL.String(),
-			Duration:    duration,
+			StatusCode:     resp.StatusCode,
+			Body:           body,
+			Headers:        resp.Header,
+			URL:            url,
+			ContentType:    resp.Header.Get("Content-Type"),
+			FinalURL:       resp.Request.URL.String(),
+			Duration:       duration,
+			RequestLine:    fmt.Sprintf("GET %s HTTP/1.1", resp.Request.URL.RequestURI()),
+			RequestHeaders: req.Header.Clone(),
+			RemoteAddr:     remoteAddr,
 		}
 
 		c.logger.Info("request successful",
@@ -180,15 +250,85 @@ func (c *HTTPClient) Fetch(ctx context.Context, url string) (*FetchResponse, err
 		return response, nil
 	}
 
-	return nil, fmt.Errorf("failed after %d attempts: %w", c.config.MaxRetries+1, lastErr)
+	return nil, fmt.Errorf("failed after %d attempts: %w", cfg.MaxRetries+1, lastErr)
+}
+
+// newProxyDialer builds a context-aware dialer for a socks5:// or
+// socks5h:// proxy URL, for routing requests (including .onion hosts)
+// through a local Tor daemon
+func newProxyDialer(proxyURL string) (proxy.ContextDialer, error) {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+	}
+
+	dialer, err := proxy.FromURL(parsed, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proxy dialer: %w", err)
+	}
+
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("proxy scheme %q does not support context-aware dialing", parsed.Scheme)
+	}
+
+	return contextDialer, nil
 }
 
 // shouldRetry determines if a request should be retried based on status code
 func (c *HTTPClient) shouldRetry(statusCode int) bool {
+	// 304 Not Modified is a successful conditional-request outcome, not a
+	// failure, so it must never be retried even though nothing was read.
+	if statusCode == http.StatusNotModified {
+		return false
+	}
 	// Retry on server errors and some client errors
 	return statusCode >= 500 || statusCode == 429 || statusCode == 408
 }
 
+// getConfig returns a snapshot of the client's current config, so a Fetch or
+// Head in flight sees a consistent set of values even if UpdateConfig runs
+// concurrently.
+func (c *HTTPClient) getConfig() ClientConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
+// ClientConfigUpdate carries the subset of ClientConfig an admin API may
+// hot-swap at runtime. A zero-valued field leaves the current value alone.
+type ClientConfigUpdate struct {
+	Timeout    time.Duration
+	MaxRetries int
+	UserAgent  string
+}
+
+// UpdateConfig applies update's non-zero fields under mu. Requests already
+// in flight keep whatever config they snapshotted via getConfig, and
+// whatever *http.Client they loaded; only subsequent calls see the new
+// values.
+func (c *HTTPClient) UpdateConfig(update ClientConfigUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if update.Timeout > 0 {
+		c.config.Timeout = update.Timeout
+
+		// Swap in a new *http.Client with the updated Timeout instead of
+		// mutating the live one's field: Do() reads Timeout on every call
+		// with no synchronization of its own.
+		updated := *c.client.Load()
+		updated.Timeout = update.Timeout
+		c.client.Store(&updated)
+	}
+	if update.MaxRetries > 0 {
+		c.config.MaxRetries = update.MaxRetries
+	}
+	if update.UserAgent != "" {
+		c.config.UserAgent = update.UserAgent
+	}
+}
+
 // Head performs an HTTP HEAD request
 func (c *HTTPClient) Head(ctx context.Context, url string) (*FetchResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
@@ -196,9 +336,9 @@ func (c *HTTPClient) Head(ctx context.Context, url string) (*FetchResponse, erro
 		return nil, fmt.Errorf("failed to create HEAD request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", c.config.UserAgent)
+	req.Header.Set("User-Agent", c.getConfig().UserAgent)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.client.Load().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HEAD request failed: %w", err)
 	}
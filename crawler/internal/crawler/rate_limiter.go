@@ -2,25 +2,121 @@ package crawler
 
 import (
 	"context"
+	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
 )
 
+// Defaults for RateLimiterConfig's adaptive (AIMD) knobs.
+const (
+	defaultAdaptiveStep           rate.Limit = 0.1
+	defaultAdaptiveCeilingMult               = 10
+	defaultAdaptiveFloor          rate.Limit = 0.05
+	defaultBackoffFactor                     = 0.5
+	defaultConnErrorBackoffFactor            = 0.75
+)
+
+// limiterEntry pairs a domain's rate.Limiter with the last time it was
+// used, so RateLimiter.cleanup can evict limiters for domains the crawler
+// has stopped visiting. lastUsed is a Unix-nano timestamp updated via
+// atomic so the common case (bumping it on every Wait/Allow) doesn't need
+// RateLimiter's map-wide mutex. adjMu serializes increaseRate/scaleRate's
+// read-modify-write of the limiter's rate, since two Observe calls for the
+// same domain racing on Limit()+SetLimit() would otherwise drop an update.
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed atomic.Int64
+	adjMu    sync.Mutex
+}
+
+func (e *limiterEntry) touch() {
+	e.lastUsed.Store(time.Now().UnixNano())
+}
+
+func (e *limiterEntry) idleSince(cutoff time.Time) bool {
+	return time.Unix(0, e.lastUsed.Load()).Before(cutoff)
+}
+
 // RateLimiter manages rate limiting per domain
 type RateLimiter struct {
-	limiters map[string]*rate.Limiter
+	limiters map[string]*limiterEntry
 	mu       sync.RWMutex
 	config   RateLimiterConfig
 }
 
 // RateLimiterConfig holds configuration for rate limiting
 type RateLimiterConfig struct {
-	DefaultRate       rate.Limit    // requests per second
-	DefaultBurst      int           // burst size
-	CleanupInterval   time.Duration // how often to clean up unused limiters
-	IdleTimeout       time.Duration // remove limiters idle for this duration
+	DefaultRate     rate.Limit    // requests per second
+	DefaultBurst    int           // burst size
+	CleanupInterval time.Duration // how often to clean up unused limiters
+	IdleTimeout     time.Duration // remove limiters idle for this duration
+
+	// AdaptiveStep is the additive increase applied to a domain's rate on
+	// each 2xx response Observe sees, in requests/second. Defaults to 0.1.
+	AdaptiveStep rate.Limit
+	// AdaptiveCeiling caps how high Observe's additive increase can push a
+	// domain's rate. Defaults to 10x DefaultRate.
+	AdaptiveCeiling rate.Limit
+	// AdaptiveFloor is the minimum rate Observe's multiplicative decrease
+	// can leave a domain at. Defaults to 0.05 req/s.
+	AdaptiveFloor rate.Limit
+	// BackoffFactor multiplies a domain's rate on a 429/503 response.
+	// Defaults to 0.5 (halve the rate).
+	BackoffFactor float64
+	// ConnErrorBackoffFactor multiplies a domain's rate on a connection
+	// error. Smaller cut than BackoffFactor, since a dropped connection is
+	// a weaker backpressure signal than an explicit 429/503. Defaults to
+	// 0.75.
+	ConnErrorBackoffFactor float64
+}
+
+func (c RateLimiterConfig) adaptiveStep() rate.Limit {
+	if c.AdaptiveStep <= 0 {
+		return defaultAdaptiveStep
+	}
+	return c.AdaptiveStep
+}
+
+func (c RateLimiterConfig) adaptiveCeiling() rate.Limit {
+	if c.AdaptiveCeiling > 0 {
+		return c.AdaptiveCeiling
+	}
+	if c.DefaultRate > 0 {
+		return c.DefaultRate * defaultAdaptiveCeilingMult
+	}
+	return rate.Limit(defaultAdaptiveCeilingMult)
+}
+
+func (c RateLimiterConfig) adaptiveFloor() rate.Limit {
+	if c.AdaptiveFloor <= 0 {
+		return defaultAdaptiveFloor
+	}
+	return c.AdaptiveFloor
+}
+
+func (c RateLimiterConfig) backoffFactor() float64 {
+	if c.BackoffFactor <= 0 || c.BackoffFactor >= 1 {
+		return defaultBackoffFactor
+	}
+	return c.BackoffFactor
+}
+
+func (c RateLimiterConfig) connErrorBackoffFactor() float64 {
+	if c.ConnErrorBackoffFactor <= 0 || c.ConnErrorBackoffFactor >= 1 {
+		return defaultConnErrorBackoffFactor
+	}
+	return c.ConnErrorBackoffFactor
+}
+
+func (c RateLimiterConfig) cleanupInterval() time.Duration {
+	if c.CleanupInterval <= 0 {
+		return 5 * time.Minute
+	}
+	return c.CleanupInterval
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -39,7 +135,7 @@ func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
 	}
 
 	rl := &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
+		limiters: make(map[string]*limiterEntry),
 		config:   config,
 	}
 
@@ -51,58 +147,104 @@ func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
 
 // Wait waits until the rate limiter allows the request for the given domain
 func (rl *RateLimiter) Wait(ctx context.Context, domain string) error {
-	limiter := rl.getLimiter(domain)
-	return limiter.Wait(ctx)
+	entry := rl.getEntry(domain)
+	return entry.limiter.Wait(ctx)
 }
 
 // Allow checks if a request is allowed for the given domain
 func (rl *RateLimiter) Allow(domain string) bool {
-	limiter := rl.getLimiter(domain)
-	return limiter.Allow()
+	entry := rl.getEntry(domain)
+	return entry.limiter.Allow()
 }
 
 // SetLimit sets a custom rate limit for a specific domain
 func (rl *RateLimiter) SetLimit(domain string, r rate.Limit, burst int) {
+	entry := &limiterEntry{limiter: rate.NewLimiter(r, burst)}
+	entry.touch()
+
 	rl.mu.Lock()
-	defer rl.mu.Unlock()
+	rl.limiters[domain] = entry
+	rl.mu.Unlock()
+}
+
+// increaseRate applies the configured additive increase to domain's current
+// rate, capped at the configured ceiling. This is AIMD's "additive
+// increase", applied on a successful response. adjMu makes the read of
+// Limit() and the subsequent SetLimit() atomic with respect to other
+// increaseRate/scaleRate calls for the same domain.
+func (rl *RateLimiter) increaseRate(domain string) {
+	entry := rl.getEntry(domain)
+
+	entry.adjMu.Lock()
+	defer entry.adjMu.Unlock()
 
-	limiter := rate.NewLimiter(r, burst)
-	rl.limiters[domain] = limiter
+	newLimit := entry.limiter.Limit() + rl.config.adaptiveStep()
+	if ceiling := rl.config.adaptiveCeiling(); newLimit > ceiling {
+		newLimit = ceiling
+	}
+	entry.limiter.SetLimit(newLimit)
+}
+
+// scaleRate multiplies domain's current rate by factor, floored at the
+// configured minimum. This is AIMD's "multiplicative decrease", applied on
+// backpressure (429/503, or a weaker cut for a connection error).
+func (rl *RateLimiter) scaleRate(domain string, factor float64) {
+	entry := rl.getEntry(domain)
+
+	entry.adjMu.Lock()
+	defer entry.adjMu.Unlock()
+
+	newLimit := rate.Limit(float64(entry.limiter.Limit()) * factor)
+	if floor := rl.config.adaptiveFloor(); newLimit < floor {
+		newLimit = floor
+	}
+	entry.limiter.SetLimit(newLimit)
 }
 
-// getLimiter returns the rate limiter for a domain, creating one if it doesn't exist
-func (rl *RateLimiter) getLimiter(domain string) *rate.Limiter {
+// getEntry returns the limiter entry for a domain, creating one if it
+// doesn't exist, and always bumping its lastUsed so cleanup won't evict a
+// limiter that's still in active use.
+func (rl *RateLimiter) getEntry(domain string) *limiterEntry {
 	rl.mu.RLock()
-	limiter, exists := rl.limiters[domain]
+	entry, exists := rl.limiters[domain]
 	rl.mu.RUnlock()
 
 	if exists {
-		return limiter
+		entry.touch()
+		return entry
 	}
 
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
 	// Double-check after acquiring write lock
-	limiter, exists = rl.limiters[domain]
+	entry, exists = rl.limiters[domain]
 	if exists {
-		return limiter
+		entry.touch()
+		return entry
 	}
 
-	limiter = rate.NewLimiter(rl.config.DefaultRate, rl.config.DefaultBurst)
-	rl.limiters[domain] = limiter
-	return limiter
+	entry = &limiterEntry{limiter: rate.NewLimiter(rl.config.DefaultRate, rl.config.DefaultBurst)}
+	entry.touch()
+	rl.limiters[domain] = entry
+	return entry
 }
 
-// cleanup periodically removes unused limiters to prevent memory leaks
+// cleanup periodically evicts limiters idle for longer than IdleTimeout, so
+// a long-running crawler doesn't leak an entry per domain it has ever seen.
 func (rl *RateLimiter) cleanup() {
 	ticker := time.NewTicker(rl.config.CleanupInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
+		cutoff := time.Now().Add(-rl.config.IdleTimeout)
+
 		rl.mu.Lock()
-		// In a production system, you'd track last usage time
-		// For now, we keep all limiters
+		for domain, entry := range rl.limiters {
+			if entry.idleSince(cutoff) {
+				delete(rl.limiters, domain)
+			}
+		}
 		rl.mu.Unlock()
 	}
 }
@@ -111,14 +253,45 @@ func (rl *RateLimiter) cleanup() {
 type DomainRateLimiter struct {
 	rateLimiter *RateLimiter
 	delays      map[string]time.Duration
+	// nextAllowed holds, per domain, the instant a Retry-After header told
+	// us not to request again before. Wait blocks on this ahead of
+	// delegating to rateLimiter, since a 429/503's Retry-After is a hard
+	// deadline the underlying rate.Limiter's token bucket doesn't know
+	// about.
+	nextAllowed map[string]time.Time
 	mu          sync.RWMutex
 }
 
 // NewDomainRateLimiter creates a new domain-specific rate limiter
 func NewDomainRateLimiter(config RateLimiterConfig) *DomainRateLimiter {
-	return &DomainRateLimiter{
+	drl := &DomainRateLimiter{
 		rateLimiter: NewRateLimiter(config),
 		delays:      make(map[string]time.Duration),
+		nextAllowed: make(map[string]time.Time),
+	}
+
+	go drl.cleanupNextAllowed(config.cleanupInterval())
+
+	return drl
+}
+
+// cleanupNextAllowed periodically evicts expired Retry-After deadlines, so
+// a domain that was rate-limited once and never visited again doesn't
+// leak an entry in nextAllowed for the life of the process.
+func (drl *DomainRateLimiter) cleanupNextAllowed(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+
+		drl.mu.Lock()
+		for domain, until := range drl.nextAllowed {
+			if !until.After(now) {
+				delete(drl.nextAllowed, domain)
+			}
+		}
+		drl.mu.Unlock()
 	}
 }
 
@@ -136,8 +309,74 @@ func (drl *DomainRateLimiter) SetCrawlDelay(domain string, delay time.Duration)
 	}
 }
 
-// Wait waits for both rate limiter and crawl delay
+// SetRate overrides the requests-per-second rate for a specific domain,
+// independent of any robots.txt crawl delay (e.g. a slower default for
+// Tor .onion circuits)
+func (drl *DomainRateLimiter) SetRate(domain string, r rate.Limit, burst int) {
+	drl.rateLimiter.SetLimit(domain, r, burst)
+}
+
+// Observe applies an AIMD adjustment to domain's rate based on the outcome
+// of a fetch the crawler just made: an additive increase on a 2xx
+// response, a multiplicative decrease on 429/503 (honoring any Retry-After
+// header by blocking Wait outright until it elapses, rather than relying
+// on the rate.Limiter to happen to schedule that far out), and a smaller
+// multiplicative decrease on a connection error, since a dropped
+// connection is a weaker backpressure signal than an explicit 429/503.
+// resp is nil when err is non-nil.
+func (drl *DomainRateLimiter) Observe(domain string, resp *FetchResponse, err error) {
+	switch {
+	case err != nil:
+		drl.rateLimiter.scaleRate(domain, drl.rateLimiter.config.connErrorBackoffFactor())
+
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		drl.rateLimiter.scaleRate(domain, drl.rateLimiter.config.backoffFactor())
+		if until, ok := parseRetryAfter(resp.Headers.Get("Retry-After")); ok {
+			drl.blockUntil(domain, until)
+		}
+
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		drl.rateLimiter.increaseRate(domain)
+	}
+}
+
+// blockUntil records that domain must not be dispatched again before until,
+// keeping the furthest-out deadline if one is already set.
+func (drl *DomainRateLimiter) blockUntil(domain string, until time.Time) {
+	drl.mu.Lock()
+	defer drl.mu.Unlock()
+
+	if existing, ok := drl.nextAllowed[domain]; !ok || until.After(existing) {
+		drl.nextAllowed[domain] = until
+	}
+}
+
+// parseRetryAfter interprets a Retry-After header value as either
+// delta-seconds or an HTTP-date, per RFC 9110 §10.2.3, returning the
+// instant it names.
+func parseRetryAfter(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second), true
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		return when, true
+	}
+
+	return time.Time{}, false
+}
+
+// Wait waits for any Retry-After backpressure, the rate limiter, and the
+// crawl delay, in that order
 func (drl *DomainRateLimiter) Wait(ctx context.Context, domain string) error {
+	if err := drl.waitForBackpressure(ctx, domain); err != nil {
+		return err
+	}
+
 	// Wait for rate limiter
 	if err := drl.rateLimiter.Wait(ctx, domain); err != nil {
 		return err
@@ -159,3 +398,27 @@ func (drl *DomainRateLimiter) Wait(ctx context.Context, domain string) error {
 
 	return nil
 }
+
+// waitForBackpressure blocks until any Retry-After deadline recorded for
+// domain by Observe has elapsed.
+func (drl *DomainRateLimiter) waitForBackpressure(ctx context.Context, domain string) error {
+	drl.mu.RLock()
+	until, ok := drl.nextAllowed[domain]
+	drl.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	wait := time.Until(until)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,154 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// TestParseRetryAfter covers delta-seconds, HTTP-date, and invalid/empty
+// Retry-After header values.
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantOK  bool
+		wantRel time.Duration // only checked when wantOK and value is delta-seconds
+	}{
+		{name: "delta-seconds", value: "120", wantOK: true, wantRel: 120 * time.Second},
+		{name: "zero delta-seconds", value: "0", wantOK: true, wantRel: 0},
+		{name: "http-date", value: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), wantOK: true},
+		{name: "empty", value: "", wantOK: false},
+		{name: "garbage", value: "not-a-value", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("parseRetryAfter(%q) ok = %v; want %v", tt.value, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tt.wantRel > 0 || tt.name == "zero delta-seconds" {
+				wantAbout := time.Now().Add(tt.wantRel)
+				if diff := got.Sub(wantAbout); diff > time.Second || diff < -time.Second {
+					t.Errorf("parseRetryAfter(%q) = %v; want about %v", tt.value, got, wantAbout)
+				}
+			}
+		})
+	}
+}
+
+// TestRateLimiter_IncreaseRate covers additive increase and its ceiling.
+func TestRateLimiter_IncreaseRate(t *testing.T) {
+	rl := NewRateLimiter(RateLimiterConfig{
+		DefaultRate:     rate.Limit(1),
+		AdaptiveStep:    rate.Limit(1),
+		AdaptiveCeiling: rate.Limit(2),
+	})
+
+	rl.increaseRate("example.com")
+	if got := rl.getEntry("example.com").limiter.Limit(); got != 2 {
+		t.Errorf("after one increase, Limit() = %v; want 2", got)
+	}
+
+	// A second increase would exceed the ceiling, so it should clamp instead.
+	rl.increaseRate("example.com")
+	if got := rl.getEntry("example.com").limiter.Limit(); got != 2 {
+		t.Errorf("after second increase, Limit() = %v; want clamped to ceiling 2", got)
+	}
+}
+
+// TestRateLimiter_ScaleRate covers multiplicative decrease and its floor.
+func TestRateLimiter_ScaleRate(t *testing.T) {
+	rl := NewRateLimiter(RateLimiterConfig{
+		DefaultRate:   rate.Limit(4),
+		AdaptiveFloor: rate.Limit(1.5),
+	})
+
+	rl.scaleRate("example.com", 0.5)
+	if got := rl.getEntry("example.com").limiter.Limit(); got != 2 {
+		t.Errorf("after one scale by 0.5, Limit() = %v; want 2", got)
+	}
+
+	// A second halving would go below the floor, so it should clamp instead.
+	rl.scaleRate("example.com", 0.5)
+	if got := rl.getEntry("example.com").limiter.Limit(); got != 1.5 {
+		t.Errorf("after second scale, Limit() = %v; want clamped to floor 1.5", got)
+	}
+}
+
+// TestDomainRateLimiter_SetCrawlDelay covers that a crawl delay is converted
+// to the equivalent requests-per-second rate.
+func TestDomainRateLimiter_SetCrawlDelay(t *testing.T) {
+	drl := NewDomainRateLimiter(RateLimiterConfig{})
+
+	drl.SetCrawlDelay("example.com", 2*time.Second)
+
+	got := drl.rateLimiter.getEntry("example.com").limiter.Limit()
+	want := rate.Limit(0.5)
+	if diff := float64(got - want); diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("after SetCrawlDelay(2s), Limit() = %v; want %v", got, want)
+	}
+}
+
+// TestDomainRateLimiter_Observe covers Observe's AIMD adjustment on each
+// outcome class: 2xx increases the rate, 429/503 decreases it, and a
+// connection error applies a smaller decrease.
+func TestDomainRateLimiter_Observe(t *testing.T) {
+	tests := []struct {
+		name       string
+		resp       *FetchResponse
+		err        error
+		wantHigher bool
+		wantLower  bool
+	}{
+		{name: "2xx increases rate", resp: &FetchResponse{StatusCode: http.StatusOK, Headers: http.Header{}}, wantHigher: true},
+		{name: "429 decreases rate", resp: &FetchResponse{StatusCode: http.StatusTooManyRequests, Headers: http.Header{}}, wantLower: true},
+		{name: "503 decreases rate", resp: &FetchResponse{StatusCode: http.StatusServiceUnavailable, Headers: http.Header{}}, wantLower: true},
+		{name: "connection error decreases rate", err: errors.New("connection reset by peer"), wantLower: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drl := NewDomainRateLimiter(RateLimiterConfig{DefaultRate: rate.Limit(1)})
+
+			before := drl.rateLimiter.getEntry("example.com").limiter.Limit()
+			drl.Observe("example.com", tt.resp, tt.err)
+			after := drl.rateLimiter.getEntry("example.com").limiter.Limit()
+
+			if tt.wantHigher && after <= before {
+				t.Errorf("Observe() left rate at %v; want higher than %v", after, before)
+			}
+			if tt.wantLower && after >= before {
+				t.Errorf("Observe() left rate at %v; want lower than %v", after, before)
+			}
+		})
+	}
+}
+
+// TestDomainRateLimiter_Observe_RetryAfter covers that a 429/503 with a
+// Retry-After header blocks the domain via blockUntil/waitForBackpressure.
+func TestDomainRateLimiter_Observe_RetryAfter(t *testing.T) {
+	drl := NewDomainRateLimiter(RateLimiterConfig{DefaultRate: rate.Limit(100)})
+
+	headers := http.Header{}
+	headers.Set("Retry-After", "60")
+	drl.Observe("example.com", &FetchResponse{StatusCode: http.StatusTooManyRequests, Headers: headers}, nil)
+
+	drl.mu.RLock()
+	until, ok := drl.nextAllowed["example.com"]
+	drl.mu.RUnlock()
+
+	if !ok {
+		t.Fatal("Observe() with Retry-After did not record a nextAllowed deadline")
+	}
+	if until.Before(time.Now().Add(59 * time.Second)) {
+		t.Errorf("nextAllowed = %v; want at least 60s out", until)
+	}
+}
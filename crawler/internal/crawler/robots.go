@@ -4,28 +4,65 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/temoto/robotstxt"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
+// maxCrawlDelay caps the Crawl-delay this crawler will honor from any single
+// robots.txt, so a hostile or misconfigured file (e.g. "Crawl-delay: 999999")
+// can't be used to stall the crawl indefinitely.
+const maxCrawlDelay = 60 * time.Second
+
+// negativeResultTTL is the (much shorter) freshness window for a cache entry
+// that represents a failed fetch (5xx/transport error), so a flapping host
+// gets re-checked every few minutes instead of on every URL, without holding
+// up every other caller for the full ttl.
+const negativeResultTTL = 5 * time.Minute
+
+// asyncRefreshTimeout bounds the background fetch stale-while-revalidate
+// kicks off; it runs detached from any request's context, so it needs its
+// own deadline.
+const asyncRefreshTimeout = 30 * time.Second
+
 // RobotsCache caches robots.txt data for domains
 type RobotsCache struct {
-	cache  map[string]*robotsCacheEntry
-	mu     sync.RWMutex
-	client *HTTPClient
-	logger *zap.Logger
-	ttl    time.Duration
+	cache      map[string]*robotsCacheEntry
+	mu         sync.RWMutex
+	client     *HTTPClient
+	logger     *zap.Logger
+	ttl        time.Duration
+	normalizer *URLNormalizer
+
+	// sf collapses concurrent fetches for the same domain into one network
+	// round trip, so a stampede of URLs on a host that just went cold
+	// doesn't serialize behind (or duplicate) the same request.
+	sf singleflight.Group
+
+	stopCleanup chan struct{}
+	closeOnce   sync.Once
 }
 
 type robotsCacheEntry struct {
-	robots    *robotstxt.RobotsData
-	crawlDelay time.Duration
-	sitemaps  []string
-	fetchedAt time.Time
+	robots *robotstxt.RobotsData
+	// agentDelays maps a lowercased User-agent token (or "*") to the
+	// Crawl-delay its group declared. GetCrawlDelay picks the longest
+	// matching agent for the caller's user agent, falling back to "*".
+	agentDelays map[string]time.Duration
+	sitemaps    []string
+	fetchedAt   time.Time
+	// negative marks an entry that stands in for a failed fetch (5xx or a
+	// transport error) rather than a successful 2xx/404/403 result. It
+	// ages out after negativeResultTTL instead of the full ttl, and
+	// getOrFetch returns fetchErr for it instead of treating it as "allow
+	// everything".
+	negative bool
+	fetchErr error
 }
 
 // NewRobotsCache creates a new robots.txt cache
@@ -35,10 +72,12 @@ func NewRobotsCache(client *HTTPClient, logger *zap.Logger, ttl time.Duration) *
 	}
 
 	rc := &RobotsCache{
-		cache:  make(map[string]*robotsCacheEntry),
-		client: client,
-		logger: logger,
-		ttl:    ttl,
+		cache:       make(map[string]*robotsCacheEntry),
+		client:      client,
+		logger:      logger,
+		ttl:         ttl,
+		normalizer:  NewURLNormalizer(),
+		stopCleanup: make(chan struct{}),
 	}
 
 	// Start cleanup goroutine
@@ -47,11 +86,40 @@ func NewRobotsCache(client *HTTPClient, logger *zap.Logger, ttl time.Duration) *
 	return rc
 }
 
+// Close stops the background cleanup goroutine. Callers that own a
+// RobotsCache for less than the process lifetime (e.g. tests, or a
+// supervisor that recreates one per run) must call this to avoid leaking it.
+func (rc *RobotsCache) Close() {
+	rc.closeOnce.Do(func() { close(rc.stopCleanup) })
+}
+
+// entryTTL returns how long entry stays fresh before it's eligible for
+// stale-while-revalidate, based on whether it's a negative (failed-fetch)
+// result or a normal one.
+func (rc *RobotsCache) entryTTL(entry *robotsCacheEntry) time.Duration {
+	if entry.negative {
+		return negativeResultTTL
+	}
+	return rc.ttl
+}
+
+// normalizeTarget runs targetURL through rc.normalizer and reparses it, so
+// every cache lookup keys off the same canonical domain regardless of how
+// the caller capitalized the scheme/host or whether it included a default
+// port.
+func (rc *RobotsCache) normalizeTarget(targetURL string) (*url.URL, error) {
+	normalized, err := rc.normalizer.Normalize(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	return url.Parse(normalized)
+}
+
 // IsAllowed checks if crawling a URL is allowed by robots.txt
 func (rc *RobotsCache) IsAllowed(ctx context.Context, targetURL, userAgent string) (bool, error) {
-	parsedURL, err := url.Parse(targetURL)
+	parsedURL, err := rc.normalizeTarget(targetURL)
 	if err != nil {
-		return false, fmt.Errorf("invalid URL: %w", err)
+		return false, err
 	}
 
 	domain := parsedURL.Scheme + "://" + parsedURL.Host
@@ -75,11 +143,13 @@ func (rc *RobotsCache) IsAllowed(ctx context.Context, targetURL, userAgent strin
 	return allowed, nil
 }
 
-// GetCrawlDelay returns the crawl delay for a domain
+// GetCrawlDelay returns the crawl delay robots.txt declares for userAgent,
+// picking the most specific (longest) matching User-agent group and falling
+// back to the "*" group.
 func (rc *RobotsCache) GetCrawlDelay(ctx context.Context, targetURL, userAgent string) (time.Duration, error) {
-	parsedURL, err := url.Parse(targetURL)
+	parsedURL, err := rc.normalizeTarget(targetURL)
 	if err != nil {
-		return 0, fmt.Errorf("invalid URL: %w", err)
+		return 0, err
 	}
 
 	domain := parsedURL.Scheme + "://" + parsedURL.Host
@@ -90,14 +160,37 @@ func (rc *RobotsCache) GetCrawlDelay(ctx context.Context, targetURL, userAgent s
 		return 0, err
 	}
 
-	return entry.crawlDelay, nil
+	return matchCrawlDelay(entry.agentDelays, userAgent), nil
+}
+
+// matchCrawlDelay picks the Crawl-delay of the longest agent token in
+// agentDelays that appears in userAgent, falling back to the "*" group.
+func matchCrawlDelay(agentDelays map[string]time.Duration, userAgent string) time.Duration {
+	lowerUA := strings.ToLower(userAgent)
+
+	var best time.Duration
+	bestLen := -1
+	for agent, delay := range agentDelays {
+		if agent == "*" || agent == "" {
+			continue
+		}
+		if strings.Contains(lowerUA, agent) && len(agent) > bestLen {
+			best = delay
+			bestLen = len(agent)
+		}
+	}
+
+	if bestLen >= 0 {
+		return best
+	}
+	return agentDelays["*"]
 }
 
 // GetSitemaps returns sitemap URLs from robots.txt
 func (rc *RobotsCache) GetSitemaps(ctx context.Context, targetURL string) ([]string, error) {
-	parsedURL, err := url.Parse(targetURL)
+	parsedURL, err := rc.normalizeTarget(targetURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+		return nil, err
 	}
 
 	domain := parsedURL.Scheme + "://" + parsedURL.Host
@@ -111,45 +204,98 @@ func (rc *RobotsCache) GetSitemaps(ctx context.Context, targetURL string) ([]str
 	return entry.sitemaps, nil
 }
 
-// getOrFetch retrieves robots.txt from cache or fetches it
+// getOrFetch retrieves robots.txt from cache or fetches it. A fresh entry is
+// returned directly; a stale-but-not-expired one is returned immediately
+// with an async refresh kicked off in the background (stale-while-
+// revalidate); anything older goes through a synchronous, per-domain
+// singleflight-deduplicated fetch.
 func (rc *RobotsCache) getOrFetch(ctx context.Context, robotsURL, domain string) (*robotsCacheEntry, error) {
-	// Check cache first
 	rc.mu.RLock()
 	entry, exists := rc.cache[domain]
 	rc.mu.RUnlock()
 
-	if exists && time.Since(entry.fetchedAt) < rc.ttl {
-		return entry, nil
-	}
+	if exists {
+		ttl := rc.entryTTL(entry)
+		age := time.Since(entry.fetchedAt)
 
-	// Fetch robots.txt
-	rc.mu.Lock()
-	defer rc.mu.Unlock()
+		if age < ttl {
+			if entry.negative {
+				return nil, entry.fetchErr
+			}
+			return entry, nil
+		}
 
-	// Double-check after acquiring write lock
-	entry, exists = rc.cache[domain]
-	if exists && time.Since(entry.fetchedAt) < rc.ttl {
-		return entry, nil
+		if age < 2*ttl {
+			rc.refreshAsync(robotsURL, domain)
+			if entry.negative {
+				return nil, entry.fetchErr
+			}
+			return entry, nil
+		}
+	}
+
+	result, err, _ := rc.sf.Do(domain, func() (interface{}, error) {
+		return rc.fetch(ctx, robotsURL, domain)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result.(*robotsCacheEntry), nil
+}
 
-	// Fetch from server
+// refreshAsync kicks off a detached fetch for domain, so a caller that hit
+// the stale-while-revalidate window isn't blocked on the network. It shares
+// rc.sf with the synchronous path, so it collapses into any fetch already
+// in flight for the same domain instead of racing it.
+func (rc *RobotsCache) refreshAsync(robotsURL, domain string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), asyncRefreshTimeout)
+		defer cancel()
+
+		if _, err, _ := rc.sf.Do(domain, func() (interface{}, error) {
+			return rc.fetch(ctx, robotsURL, domain)
+		}); err != nil {
+			rc.logger.Warn("background robots.txt refresh failed",
+				zap.String("domain", domain),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// fetch retrieves robots.txt over the network, stores the resulting entry
+// (positive or negative) in the cache, and returns it. It never returns a
+// nil entry together with a nil error.
+func (rc *RobotsCache) fetch(ctx context.Context, robotsURL, domain string) (*robotsCacheEntry, error) {
 	resp, err := rc.client.Fetch(ctx, robotsURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+		fetchErr := fmt.Errorf("failed to fetch robots.txt: %w", err)
+		rc.store(domain, &robotsCacheEntry{
+			negative:  true,
+			fetchErr:  fetchErr,
+			fetchedAt: time.Now(),
+		})
+		return nil, fetchErr
 	}
 
 	if resp.StatusCode == 404 || resp.StatusCode == 403 {
 		// No robots.txt, allow everything
-		entry = &robotsCacheEntry{
+		entry := &robotsCacheEntry{
 			robots:    nil,
 			fetchedAt: time.Now(),
 		}
-		rc.cache[domain] = entry
+		rc.store(domain, entry)
 		return entry, nil
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		fetchErr := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		rc.store(domain, &robotsCacheEntry{
+			negative:  true,
+			fetchErr:  fetchErr,
+			fetchedAt: time.Now(),
+		})
+		return nil, fetchErr
 	}
 
 	// Parse robots.txt
@@ -160,42 +306,100 @@ func (rc *RobotsCache) getOrFetch(ctx context.Context, robotsURL, domain string)
 			zap.Error(err),
 		)
 		// Create empty entry on parse error
-		entry = &robotsCacheEntry{
+		entry := &robotsCacheEntry{
 			robots:    nil,
 			fetchedAt: time.Now(),
 		}
-		rc.cache[domain] = entry
+		rc.store(domain, entry)
 		return entry, nil
 	}
 
 	// Extract crawl delay and sitemaps
-	crawlDelay := rc.extractCrawlDelay(robots)
+	agentDelays := rc.extractCrawlDelay(resp.Body)
 	sitemaps := rc.extractSitemaps(resp.Body)
 
-	entry = &robotsCacheEntry{
-		robots:     robots,
-		crawlDelay: crawlDelay,
-		sitemaps:   sitemaps,
-		fetchedAt:  time.Now(),
+	entry := &robotsCacheEntry{
+		robots:      robots,
+		agentDelays: agentDelays,
+		sitemaps:    sitemaps,
+		fetchedAt:   time.Now(),
 	}
-
-	rc.cache[domain] = entry
+	rc.store(domain, entry)
 
 	rc.logger.Info("robots.txt cached",
 		zap.String("domain", domain),
-		zap.Duration("crawl_delay", crawlDelay),
+		zap.Duration("crawl_delay", agentDelays["*"]),
 		zap.Int("sitemaps", len(sitemaps)),
 	)
 
 	return entry, nil
 }
 
-// extractCrawlDelay extracts crawl delay from robots.txt
-func (rc *RobotsCache) extractCrawlDelay(robots *robotstxt.RobotsData) time.Duration {
-	// The robotstxt library doesn't expose CrawlDelay directly
-	// We'd need to parse the raw content for this
-	// For now, return 0 and handle in the parser if needed
-	return 0
+// store writes entry into the cache under domain.
+func (rc *RobotsCache) store(domain string, entry *robotsCacheEntry) {
+	rc.mu.Lock()
+	rc.cache[domain] = entry
+	rc.mu.Unlock()
+}
+
+// extractCrawlDelay walks the raw robots.txt content and returns a map of
+// lowercased User-agent token (or "*") to its group's Crawl-delay. Per RFC
+// 9309, consecutive "User-agent:" lines share one group; the group ends as
+// soon as any other directive (Disallow, Allow, Crawl-delay, ...) appears, so
+// the next "User-agent:" line starts a new group.
+func (rc *RobotsCache) extractCrawlDelay(content []byte) map[string]time.Duration {
+	delays := make(map[string]time.Duration)
+	lines := strings.Split(string(content), "\n")
+
+	var currentAgents []string
+	groupClosed := false
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch field {
+		case "user-agent":
+			if groupClosed {
+				currentAgents = nil
+				groupClosed = false
+			}
+			currentAgents = append(currentAgents, strings.ToLower(value))
+
+		case "crawl-delay":
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				groupClosed = true
+				continue
+			}
+
+			delay := time.Duration(seconds * float64(time.Second))
+			if delay > maxCrawlDelay {
+				delay = maxCrawlDelay
+			}
+			for _, agent := range currentAgents {
+				delays[agent] = delay
+			}
+			groupClosed = true
+
+		default:
+			groupClosed = true
+		}
+	}
+
+	return delays
 }
 
 // extractSitemaps extracts sitemap URLs from robots.txt content
@@ -216,20 +420,27 @@ func (rc *RobotsCache) extractSitemaps(content []byte) []string {
 	return sitemaps
 }
 
-// cleanup periodically removes expired cache entries
+// cleanup periodically removes cache entries once they're too old to serve
+// even as a stale-while-revalidate fallback (2x their entryTTL). It exits
+// when Close is called.
 func (rc *RobotsCache) cleanup() {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		rc.mu.Lock()
-		now := time.Now()
-		for domain, entry := range rc.cache {
-			if now.Sub(entry.fetchedAt) > rc.ttl {
-				delete(rc.cache, domain)
+	for {
+		select {
+		case <-ticker.C:
+			rc.mu.Lock()
+			now := time.Now()
+			for domain, entry := range rc.cache {
+				if now.Sub(entry.fetchedAt) > 2*rc.entryTTL(entry) {
+					delete(rc.cache, domain)
+				}
 			}
+			rc.mu.Unlock()
+		case <-rc.stopCleanup:
+			return
 		}
-		rc.mu.Unlock()
 	}
 }
 
@@ -239,3 +450,32 @@ func (rc *RobotsCache) Invalidate(domain string) {
 	defer rc.mu.Unlock()
 	delete(rc.cache, domain)
 }
+
+// RobotsInfo is a snapshot of a domain's cached robots.txt entry, exported
+// for introspection (e.g. by an admin API) without leaking robotsCacheEntry.
+type RobotsInfo struct {
+	HasRobots   bool
+	AgentDelays map[string]time.Duration
+	Sitemaps    []string
+	FetchedAt   time.Time
+}
+
+// Inspect returns a snapshot of domain's cached robots.txt entry, if any.
+// domain must be in the same "scheme://host" form used internally (and by
+// IsAllowed/GetCrawlDelay/GetSitemaps).
+func (rc *RobotsCache) Inspect(domain string) (RobotsInfo, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	entry, ok := rc.cache[domain]
+	if !ok {
+		return RobotsInfo{}, false
+	}
+
+	return RobotsInfo{
+		HasRobots:   entry.robots != nil,
+		AgentDelays: entry.agentDelays,
+		Sitemaps:    entry.sitemaps,
+		FetchedAt:   entry.fetchedAt,
+	}, true
+}
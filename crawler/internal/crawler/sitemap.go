@@ -9,17 +9,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/seo-platform/crawler/internal/dateparser"
 	"go.uber.org/zap"
 )
 
 // SitemapParser parses XML sitemaps
 type SitemapParser struct {
-	client *HTTPClient
+	client Fetcher
 	logger *zap.Logger
 }
 
 // NewSitemapParser creates a new sitemap parser
-func NewSitemapParser(client *HTTPClient, logger *zap.Logger) *SitemapParser {
+func NewSitemapParser(client Fetcher, logger *zap.Logger) *SitemapParser {
 	return &SitemapParser{
 		client: client,
 		logger: logger,
@@ -40,10 +41,53 @@ type SitemapIndex struct {
 
 // SitemapURL represents a single URL in a sitemap
 type SitemapURL struct {
-	Loc        string  `xml:"loc"`
-	LastMod    string  `xml:"lastmod,omitempty"`
-	ChangeFreq string  `xml:"changefreq,omitempty"`
-	Priority   float64 `xml:"priority,omitempty"`
+	Loc        string              `xml:"loc"`
+	LastMod    string              `xml:"lastmod,omitempty"`
+	ChangeFreq string              `xml:"changefreq,omitempty"`
+	Priority   float64             `xml:"priority,omitempty"`
+	Images     []SitemapImage      `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+	Videos     []SitemapVideo      `xml:"http://www.google.com/schemas/sitemap-video/1.1 video"`
+	News       *SitemapNews        `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+	Alternates []HreflangAlternate `xml:"http://www.w3.org/1999/xhtml link"`
+}
+
+// SitemapImage represents a Google image sitemap extension entry
+type SitemapImage struct {
+	Loc     string `xml:"http://www.google.com/schemas/sitemap-image/1.1 loc"`
+	Caption string `xml:"http://www.google.com/schemas/sitemap-image/1.1 caption,omitempty"`
+	Title   string `xml:"http://www.google.com/schemas/sitemap-image/1.1 title,omitempty"`
+	License string `xml:"http://www.google.com/schemas/sitemap-image/1.1 license,omitempty"`
+}
+
+// SitemapVideo represents a Google video sitemap extension entry
+type SitemapVideo struct {
+	ThumbnailLoc    string `xml:"http://www.google.com/schemas/sitemap-video/1.1 thumbnail_loc"`
+	Title           string `xml:"http://www.google.com/schemas/sitemap-video/1.1 title"`
+	Description     string `xml:"http://www.google.com/schemas/sitemap-video/1.1 description"`
+	ContentLoc      string `xml:"http://www.google.com/schemas/sitemap-video/1.1 content_loc,omitempty"`
+	PlayerLoc       string `xml:"http://www.google.com/schemas/sitemap-video/1.1 player_loc,omitempty"`
+	Duration        int    `xml:"http://www.google.com/schemas/sitemap-video/1.1 duration,omitempty"`
+	PublicationDate string `xml:"http://www.google.com/schemas/sitemap-video/1.1 publication_date,omitempty"`
+}
+
+// SitemapNews represents a Google news sitemap extension entry
+type SitemapNews struct {
+	Publication     SitemapNewsPublication `xml:"http://www.google.com/schemas/sitemap-news/0.9 publication"`
+	PublicationDate string                 `xml:"http://www.google.com/schemas/sitemap-news/0.9 publication_date"`
+	Title           string                 `xml:"http://www.google.com/schemas/sitemap-news/0.9 title"`
+}
+
+// SitemapNewsPublication identifies the news publication in a news sitemap entry
+type SitemapNewsPublication struct {
+	Name     string `xml:"http://www.google.com/schemas/sitemap-news/0.9 name"`
+	Language string `xml:"http://www.google.com/schemas/sitemap-news/0.9 language"`
+}
+
+// HreflangAlternate represents an xhtml:link rel="alternate" hreflang annotation
+type HreflangAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
 }
 
 // Sitemap represents a sitemap reference in a sitemap index
@@ -114,9 +158,23 @@ func (sp *SitemapParser) Parse(ctx context.Context, sitemapURL string) (*Sitemap
 		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
 	}
 
+	imageCount, videoCount, newsCount, alternateCount := 0, 0, 0, 0
+	for _, u := range urlSet.URLs {
+		imageCount += len(u.Images)
+		videoCount += len(u.Videos)
+		if u.News != nil {
+			newsCount++
+		}
+		alternateCount += len(u.Alternates)
+	}
+
 	sp.logger.Info("parsed sitemap",
 		zap.String("url", sitemapURL),
 		zap.Int("urls", len(urlSet.URLs)),
+		zap.Int("images", imageCount),
+		zap.Int("videos", videoCount),
+		zap.Int("news", newsCount),
+		zap.Int("alternates", alternateCount),
 	)
 
 	return &SitemapResult{
@@ -127,7 +185,31 @@ func (sp *SitemapParser) Parse(ctx context.Context, sitemapURL string) (*Sitemap
 
 // ParseRecursive recursively parses a sitemap and all referenced sitemaps
 func (sp *SitemapParser) ParseRecursive(ctx context.Context, sitemapURL string, maxDepth int) ([]SitemapURL, error) {
-	return sp.parseRecursiveHelper(ctx, sitemapURL, 0, maxDepth)
+	urls, err := sp.parseRecursiveHelper(ctx, sitemapURL, 0, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	imageCount, videoCount, newsCount, alternateCount := 0, 0, 0, 0
+	for _, u := range urls {
+		imageCount += len(u.Images)
+		videoCount += len(u.Videos)
+		if u.News != nil {
+			newsCount++
+		}
+		alternateCount += len(u.Alternates)
+	}
+
+	sp.logger.Info("aggregated recursive sitemap",
+		zap.String("url", sitemapURL),
+		zap.Int("urls", len(urls)),
+		zap.Int("images", imageCount),
+		zap.Int("videos", videoCount),
+		zap.Int("news", newsCount),
+		zap.Int("alternates", alternateCount),
+	)
+
+	return urls, nil
 }
 
 func (sp *SitemapParser) parseRecursiveHelper(ctx context.Context, sitemapURL string, depth, maxDepth int) ([]SitemapURL, error) {
@@ -165,24 +247,5 @@ func (sp *SitemapParser) parseRecursiveHelper(ctx context.Context, sitemapURL st
 
 // ParseLastModified parses the lastmod field into a time.Time
 func ParseLastModified(lastMod string) (time.Time, error) {
-	if lastMod == "" {
-		return time.Time{}, nil
-	}
-
-	// Try different date formats
-	formats := []string{
-		time.RFC3339,
-		"2006-01-02T15:04:05-07:00",
-		"2006-01-02T15:04:05Z",
-		"2006-01-02",
-	}
-
-	for _, format := range formats {
-		t, err := time.Parse(format, lastMod)
-		if err == nil {
-			return t, nil
-		}
-	}
-
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", lastMod)
+	return dateparser.Parse(lastMod)
 }
@@ -0,0 +1,275 @@
+package crawler
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// trackingParams are query parameters that identify a traffic source rather
+// than the resource itself (plus any utm_* prefixed parameter), so two URLs
+// that differ only by these collapse to the same canonical form.
+var trackingParams = map[string]bool{
+	"gclid":   true,
+	"fbclid":  true,
+	"msclkid": true,
+}
+
+// URLNormalizer collapses syntactically distinct URLs that address the same
+// resource into one canonical string: scheme/host case, default ports, dot
+// segments, fragments, percent-escape case, query parameter order, and
+// tracking parameters all stop mattering. RobotsCache, PostgresStorage, and
+// the crawl frontier all normalize through the same instance so a page keyed
+// one way by one caller is recognized by the others.
+type URLNormalizer struct {
+	trackingParams map[string]bool
+}
+
+// NewURLNormalizer creates a URLNormalizer. extraTrackingParams are stripped
+// from every URL's query string in addition to the built-in gclid/fbclid/
+// msclkid and any utm_*-prefixed parameter.
+func NewURLNormalizer(extraTrackingParams ...string) *URLNormalizer {
+	params := make(map[string]bool, len(trackingParams)+len(extraTrackingParams))
+	for p := range trackingParams {
+		params[p] = true
+	}
+	for _, p := range extraTrackingParams {
+		params[strings.ToLower(p)] = true
+	}
+
+	return &URLNormalizer{trackingParams: params}
+}
+
+// Normalize reduces rawURL to its canonical string form: lowercase
+// scheme/host, punycode IDN host, default ports stripped, dot segments
+// collapsed, fragment dropped, query parameters sorted with tracking
+// parameters removed, and trailing slash stripped from non-root paths.
+func (n *URLNormalizer) Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Fragment = ""
+
+	host, err := normalizeHost(u.Host, u.Scheme)
+	if err != nil {
+		return "", fmt.Errorf("invalid host: %w", err)
+	}
+	u.Host = host
+
+	escapedPath := normalizePath(u.EscapedPath())
+	if len(escapedPath) > 1 && strings.HasSuffix(escapedPath, "/") {
+		escapedPath = strings.TrimSuffix(escapedPath, "/")
+	}
+
+	decodedPath, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path encoding: %w", err)
+	}
+	u.Path = decodedPath
+	u.RawPath = escapedPath
+
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+
+	return u.String(), nil
+}
+
+// SURT converts rawURL to Sort-friendly URI Reordering Transform form after
+// normalizing it: the host's labels reversed and comma-joined, followed by
+// the path and query, e.g. "http://example.com/a/c?a=1&b=2" becomes
+// "com,example)/a/c?a=1&b=2". This is suitable as a DB index key (domains
+// and their subdomains sort adjacently) or a CDX line key.
+func (n *URLNormalizer) SURT(rawURL string) (string, error) {
+	normalized, err := n.Normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	host, port, splitErr := net.SplitHostPort(u.Host)
+	if splitErr != nil {
+		host = u.Host
+		port = ""
+	}
+
+	labels := strings.Split(host, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	reversedHost := strings.Join(labels, ",")
+	if port != "" {
+		reversedHost += ":" + port
+	}
+
+	surt := reversedHost + ")" + u.Path
+	if u.RawQuery != "" {
+		surt += "?" + u.RawQuery
+	}
+	return surt, nil
+}
+
+// normalizeHost lowercases host, converts an IDN hostname to its punycode
+// ("xn--...") form, and strips the port if it's the scheme's default.
+func normalizeHost(host, scheme string) (string, error) {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+		port = ""
+	}
+	hostname = strings.ToLower(hostname)
+
+	bare := strings.TrimSuffix(strings.TrimPrefix(hostname, "["), "]")
+	if net.ParseIP(bare) == nil && hostname != "" {
+		ascii, err := idna.ToASCII(hostname)
+		if err != nil {
+			return "", err
+		}
+		hostname = ascii
+	}
+
+	if port == "" || isDefaultPort(scheme, port) {
+		return hostname, nil
+	}
+	return hostname + ":" + port, nil
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
+
+// normalizePath takes the escaped (still percent-encoded) path, decodes
+// unreserved percent-escapes and uppercases the rest, then collapses dot
+// segments ("/a/./b/../c" -> "/a/c"), preserving a trailing slash the input
+// had so callers can still choose whether to trim it. path.Clean only splits
+// on literal "/" bytes, so an escaped "%2F" (a literal slash within a
+// segment) is left alone rather than being treated as a path boundary.
+func normalizePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	p = normalizePercentEscapes(p)
+
+	hadTrailingSlash := strings.HasSuffix(p, "/")
+	cleaned := path.Clean(p)
+	if cleaned == "." {
+		cleaned = "/"
+	}
+	if !strings.HasPrefix(cleaned, "/") {
+		cleaned = "/" + cleaned
+	}
+	if hadTrailingSlash && cleaned != "/" && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// isUnreserved reports whether b is one of RFC 3986's unreserved characters
+// (ALPHA / DIGIT / "-" / "." / "_" / "~"), which never need escaping.
+func isUnreserved(b byte) bool {
+	switch {
+	case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z', b >= '0' && b <= '9':
+		return true
+	case b == '-' || b == '.' || b == '_' || b == '~':
+		return true
+	}
+	return false
+}
+
+// normalizePercentEscapes decodes percent-escapes of unreserved characters
+// to their literal form and uppercases the hex digits of every escape that
+// remains (RFC 3986 §6.2.2.1/6.2.2.2), so two URLs differing only in
+// percent-escape casing or unreserved-character escaping canonicalize to the
+// same string. Reserved-character escapes like "%2F" are left percent-encoded,
+// just with uppercase hex, so they keep meaning "literal slash within a
+// segment" rather than a path separator.
+func normalizePercentEscapes(escaped string) string {
+	var b strings.Builder
+	b.Grow(len(escaped))
+
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] != '%' || i+2 >= len(escaped) {
+			b.WriteByte(escaped[i])
+			continue
+		}
+
+		hi, okHi := fromHex(escaped[i+1])
+		lo, okLo := fromHex(escaped[i+2])
+		if !okHi || !okLo {
+			b.WriteByte(escaped[i])
+			continue
+		}
+
+		decoded := hi<<4 | lo
+		if isUnreserved(decoded) {
+			b.WriteByte(decoded)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(upperHex(hi))
+			b.WriteByte(upperHex(lo))
+		}
+		i += 2
+	}
+
+	return b.String()
+}
+
+// fromHex decodes a single hex digit.
+func fromHex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// upperHex renders a decoded hex digit (0-15) as its uppercase character.
+func upperHex(n byte) byte {
+	if n < 10 {
+		return '0' + n
+	}
+	return 'A' + n - 10
+}
+
+// normalizeQuery parses rawQuery, drops tracking parameters, and re-encodes
+// it with keys sorted alphabetically (url.Values.Encode does this for us).
+func (n *URLNormalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+
+	for key := range values {
+		if n.isTrackingParam(key) {
+			values.Del(key)
+		}
+	}
+
+	return values.Encode()
+}
+
+func (n *URLNormalizer) isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "utm_") {
+		return true
+	}
+	return n.trackingParams[key]
+}
@@ -0,0 +1,91 @@
+package crawler
+
+import "testing"
+
+// TestURLNormalizer_Normalize covers scheme/host case, default ports, dot
+// segments, fragments, percent-escape case, query parameter ordering and
+// tracking-parameter stripping, IDN punycode, and trailing-slash handling.
+func TestURLNormalizer_Normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		extra   []string
+		want    string
+		wantErr bool
+	}{
+		{name: "lowercases scheme and host", raw: "HTTP://Example.COM/path", want: "http://example.com/path"},
+		{name: "strips default http port", raw: "http://example.com:80/path", want: "http://example.com/path"},
+		{name: "strips default https port", raw: "https://example.com:443/path", want: "https://example.com/path"},
+		{name: "keeps non-default port", raw: "http://example.com:8080/path", want: "http://example.com:8080/path"},
+		{name: "drops fragment", raw: "http://example.com/path#section", want: "http://example.com/path"},
+		{name: "collapses dot segments", raw: "http://example.com/a/./b/../c", want: "http://example.com/a/c"},
+		{name: "trims trailing slash on non-root path", raw: "http://example.com/path/", want: "http://example.com/path"},
+		{name: "keeps root path slash", raw: "http://example.com/", want: "http://example.com/"},
+		{name: "uppercases percent-escape hex", raw: "http://example.com/%2f%2a", want: "http://example.com/%2F%2A"},
+		{name: "decodes unreserved percent-escape", raw: "http://example.com/%7Euser", want: "http://example.com/~user"},
+		{name: "sorts query parameters", raw: "http://example.com/?b=2&a=1", want: "http://example.com/?a=1&b=2"},
+		{name: "strips built-in tracking parameter", raw: "http://example.com/?a=1&gclid=abc", want: "http://example.com/?a=1"},
+		{name: "strips utm_ prefixed parameters", raw: "http://example.com/?a=1&utm_source=x&utm_campaign=y", want: "http://example.com/?a=1"},
+		{name: "strips extra tracking parameter", raw: "http://example.com/?a=1&ref=abc", extra: []string{"ref"}, want: "http://example.com/?a=1"},
+		{name: "punycode-encodes IDN host", raw: "http://münchen.example/", want: "http://xn--mnchen-3ya.example/"},
+		{name: "trims surrounding whitespace", raw: "  http://example.com/path  ", want: "http://example.com/path"},
+		{name: "invalid URL returns error", raw: "http://[::1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewURLNormalizer(tt.extra...)
+			got, err := n.Normalize(tt.raw)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Normalize(%q) = %q, nil; want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q; want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestURLNormalizer_SURT covers the host-label reversal and path/query
+// passthrough that turns a normalized URL into SURT form.
+func TestURLNormalizer_SURT(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "reverses host labels", raw: "http://example.com/a/c?a=1&b=2", want: "com,example)/a/c?a=1&b=2"},
+		{name: "reverses subdomain labels", raw: "http://www.example.com/path", want: "com,example,www)/path"},
+		{name: "no query string", raw: "http://example.com/path", want: "com,example)/path"},
+		{name: "keeps non-default port", raw: "http://example.com:8080/path", want: "com,example:8080)/path"},
+		{name: "invalid URL returns error", raw: "http://[::1", wantErr: true},
+	}
+
+	n := NewURLNormalizer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := n.SURT(tt.raw)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("SURT(%q) = %q, nil; want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SURT(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("SURT(%q) = %q; want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
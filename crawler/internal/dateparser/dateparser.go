@@ -0,0 +1,197 @@
+// Package dateparser parses the many date formats seen in sitemaps, feeds,
+// and CMS output into a single time.Time, so format-guessing logic doesn't
+// get duplicated across the crawler.
+package dateparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// layouts are tried in order against the raw (or zone-substituted) string
+var layouts = []string{
+	// W3C-DTF, as allowed by the sitemap spec
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+
+	// RFC3339 with nanoseconds
+	time.RFC3339Nano,
+	time.RFC3339,
+
+	// RFC1123 variants (with/without seconds, 2/4-digit years, named/numeric zones)
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"Mon, 02 Jan 2006 15:04 MST",
+	"Mon, 02 Jan 06 15:04:05 -0700",
+	"Mon, 02 Jan 06 15:04:05 MST",
+	"Mon, 02 Jan 06 15:04 -0700",
+	"Mon, 02 Jan 06 15:04 MST",
+
+	// RFC822 variants (with/without seconds, 2/4-digit years, named/numeric zones)
+	time.RFC822Z,
+	time.RFC822,
+	"02 Jan 06 15:04:05 -0700",
+	"02 Jan 06 15:04:05 MST",
+	"02 Jan 2006 15:04 -0700",
+	"02 Jan 2006 15:04 MST",
+	"02 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 MST",
+
+	// RFC850
+	time.RFC850,
+
+	// ANSI-C and friends
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+
+	// Common CMS / database output
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+}
+
+// tzAbbreviations resolves timezone abbreviations that time.Parse cannot
+// compute an offset for on its own (it accepts the token but leaves the
+// offset at zero, silently producing the wrong instant).
+var tzAbbreviations = map[string]int{
+	"UTC":  0,
+	"GMT":  0,
+	"EST":  -5 * 3600,
+	"EDT":  -4 * 3600,
+	"CST":  -6 * 3600,
+	"CDT":  -5 * 3600,
+	"MST":  -7 * 3600,
+	"MDT":  -6 * 3600,
+	"PST":  -8 * 3600,
+	"PDT":  -7 * 3600,
+	"BST":  1 * 3600,
+	"CET":  1 * 3600,
+	"CEST": 2 * 3600,
+	"EET":  2 * 3600,
+	"EEST": 3 * 3600,
+	"JST":  9 * 3600,
+	"IST":  5*3600 + 1800,
+	"AEST": 10 * 3600,
+	"AEDT": 11 * 3600,
+}
+
+var digitsOnly = regexp.MustCompile(`^-?\d+$`)
+
+// Parse parses a date string in any of the sitemap, feed, or common CMS
+// formats into a time.Time
+func Parse(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	if digitsOnly.MatchString(raw) {
+		if t, ok := parseEpoch(raw); ok {
+			return t, nil
+		}
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			// time.Parse accepts a zone name it has no offset for (e.g. PST)
+			// and leaves it at +0000: resolve a trailing one through
+			// tzAbbreviations instead, keeping t if that doesn't parse.
+			if name, offset := t.Zone(); offset == 0 && name != "" && name != "UTC" && name != "GMT" {
+				if resolved, ok := tryWithResolvedAbbreviation(raw); ok {
+					return resolved, nil
+				}
+			}
+			return t, nil
+		}
+	}
+
+	// No layout accepts raw as it stands (e.g. a CMS datetime followed by a
+	// zone name): substitute the trailing abbreviation's numeric offset and
+	// retry.
+	if t, ok := tryWithResolvedAbbreviation(raw); ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", raw)
+}
+
+// parseEpoch interprets a pure-digit string as a Unix timestamp, in seconds
+// or milliseconds depending on magnitude
+func parseEpoch(raw string) (time.Time, bool) {
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+
+	switch {
+	case abs >= 1e12:
+		return time.UnixMilli(n), true
+	case abs >= 1e8:
+		return time.Unix(n, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// tryWithResolvedAbbreviation looks for a trailing timezone abbreviation,
+// substitutes its known numeric offset, and retries the layout table.
+// Unrecognized abbreviations fall back to UTC with a logged warning.
+func tryWithResolvedAbbreviation(raw string) (time.Time, bool) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+
+	abbrev := strings.ToUpper(fields[len(fields)-1])
+	offsetSeconds, known := tzAbbreviations[abbrev]
+
+	prefix := strings.TrimSpace(strings.Join(fields[:len(fields)-1], " "))
+
+	if !known {
+		zap.L().Warn("unrecognized timezone abbreviation, assuming UTC",
+			zap.String("raw", raw),
+			zap.String("abbreviation", abbrev),
+		)
+		offsetSeconds = 0
+	}
+
+	numericOffset := formatOffset(offsetSeconds)
+	substituted := prefix + " " + numericOffset
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, substituted); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// formatOffset renders a UTC offset in seconds as +HHMM/-HHMM
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+}
@@ -0,0 +1,111 @@
+package dateparser
+
+import "testing"
+
+// TestParse covers the W3C-DTF, RFC3339/RFC3339Nano, RFC1123/RFC822 (with
+// and without seconds, 2- or 4-digit years, numeric or named zones), RFC850,
+// ANSI-C/UnixDate/RubyDate, common CMS, and Unix epoch formats Parse accepts,
+// plus the timezone-abbreviation fallback path and its error cases.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		// want is the expected instant, formatted as RFC3339Nano in UTC.
+		// Ignored when wantZero or wantErr is set.
+		want     string
+		wantZero bool
+		wantErr  bool
+	}{
+		// W3C-DTF, as allowed by the sitemap spec
+		{name: "w3c year", raw: "2023", want: "2023-01-01T00:00:00Z"},
+		{name: "w3c year-month", raw: "2023-06", want: "2023-06-01T00:00:00Z"},
+		{name: "w3c date", raw: "2023-06-15", want: "2023-06-15T00:00:00Z"},
+		{name: "w3c datetime minutes Z", raw: "2023-06-15T10:30Z", want: "2023-06-15T10:30:00Z"},
+		{name: "w3c datetime seconds Z", raw: "2023-06-15T10:30:45Z", want: "2023-06-15T10:30:45Z"},
+		{name: "w3c datetime fractional Z", raw: "2023-06-15T10:30:45.500Z", want: "2023-06-15T10:30:45.5Z"},
+		{name: "w3c datetime numeric offset", raw: "2023-06-15T10:30:45+02:00", want: "2023-06-15T08:30:45Z"},
+		{name: "w3c datetime fractional offset", raw: "2023-06-15T10:30:45.250-05:00", want: "2023-06-15T15:30:45.25Z"},
+
+		// RFC3339 / RFC3339Nano
+		{name: "rfc3339", raw: "2023-06-15T10:30:45Z", want: "2023-06-15T10:30:45Z"},
+		{name: "rfc3339 numeric offset", raw: "2023-06-15T10:30:45+00:00", want: "2023-06-15T10:30:45Z"},
+		{name: "rfc3339 nano", raw: "2023-06-15T10:30:45.123456789Z", want: "2023-06-15T10:30:45.123456789Z"},
+
+		// RFC1123 variants (with/without seconds, 2/4-digit years, named/numeric zones)
+		{name: "rfc1123z", raw: "Thu, 15 Jun 2023 15:04:05 +0000", want: "2023-06-15T15:04:05Z"},
+		{name: "rfc1123", raw: "Thu, 15 Jun 2023 15:04:05 UTC", want: "2023-06-15T15:04:05Z"},
+		{name: "rfc1123 no seconds, numeric zone", raw: "Thu, 15 Jun 2023 15:04 -0700", want: "2023-06-15T22:04:00Z"},
+		{name: "rfc1123 no seconds, named zone", raw: "Thu, 15 Jun 2023 15:04 UTC", want: "2023-06-15T15:04:00Z"},
+		{name: "rfc1123 2-digit year, numeric zone", raw: "Thu, 15 Jun 23 15:04:05 -0700", want: "2023-06-15T22:04:05Z"},
+		{name: "rfc1123 2-digit year, named zone", raw: "Thu, 15 Jun 23 15:04:05 UTC", want: "2023-06-15T15:04:05Z"},
+		{name: "rfc1123 2-digit year, no seconds, numeric zone", raw: "Thu, 15 Jun 23 15:04 -0700", want: "2023-06-15T22:04:00Z"},
+		{name: "rfc1123 2-digit year, no seconds, named zone", raw: "Thu, 15 Jun 23 15:04 UTC", want: "2023-06-15T15:04:00Z"},
+
+		// RFC822 variants (with/without seconds, 2/4-digit years, named/numeric zones)
+		{name: "rfc822z", raw: "15 Jun 23 15:04 +0000", want: "2023-06-15T15:04:00Z"},
+		{name: "rfc822", raw: "15 Jun 23 15:04 UTC", want: "2023-06-15T15:04:00Z"},
+		{name: "rfc822 with seconds, numeric zone", raw: "15 Jun 23 15:04:05 -0700", want: "2023-06-15T22:04:05Z"},
+		{name: "rfc822 with seconds, named zone", raw: "15 Jun 23 15:04:05 UTC", want: "2023-06-15T15:04:05Z"},
+		{name: "rfc822 4-digit year, numeric zone", raw: "15 Jun 2023 15:04 -0700", want: "2023-06-15T22:04:00Z"},
+		{name: "rfc822 4-digit year, named zone", raw: "15 Jun 2023 15:04 UTC", want: "2023-06-15T15:04:00Z"},
+		{name: "rfc822 4-digit year with seconds, numeric zone", raw: "15 Jun 2023 15:04:05 -0700", want: "2023-06-15T22:04:05Z"},
+		{name: "rfc822 4-digit year with seconds, named zone", raw: "15 Jun 2023 15:04:05 UTC", want: "2023-06-15T15:04:05Z"},
+
+		// RFC850
+		{name: "rfc850", raw: "Thursday, 15-Jun-23 15:04:05 UTC", want: "2023-06-15T15:04:05Z"},
+
+		// ANSI-C and friends
+		{name: "ansic", raw: "Thu Jun 15 15:04:05 2023", want: "2023-06-15T15:04:05Z"},
+		{name: "unixdate", raw: "Thu Jun 15 15:04:05 UTC 2023", want: "2023-06-15T15:04:05Z"},
+		{name: "rubydate", raw: "Thu Jun 15 15:04:05 +0000 2023", want: "2023-06-15T15:04:05Z"},
+
+		// Common CMS / database output
+		{name: "cms with numeric offset", raw: "2023-06-15 15:04:05 -0700", want: "2023-06-15T22:04:05Z"},
+		{name: "cms with Z offset", raw: "2023-06-15 15:04:05Z", want: "2023-06-15T15:04:05Z"},
+		{name: "cms no offset", raw: "2023-06-15 15:04:05", want: "2023-06-15T15:04:05Z"},
+		{name: "cms no seconds", raw: "2023-06-15 15:04", want: "2023-06-15T15:04:00Z"},
+		{name: "us-style date and time", raw: "06/15/2023 15:04:05", want: "2023-06-15T15:04:05Z"},
+		{name: "us-style date", raw: "06/15/2023", want: "2023-06-15T00:00:00Z"},
+
+		// Unix epoch, seconds or milliseconds
+		{name: "epoch seconds", raw: "1686841445", want: "2023-06-15T15:04:05Z"},
+		{name: "epoch milliseconds", raw: "1686841445500", want: "2023-06-15T15:04:05.5Z"},
+
+		// Timezone abbreviation fallback: no layout in the table already
+		// matches "<CMS datetime> <abbrev>" directly, so the primary pass
+		// fails and tryWithResolvedAbbreviation substitutes a numeric offset
+		{name: "fallback resolves known abbreviation", raw: "2023-06-15 15:04:05 PST", want: "2023-06-15T23:04:05Z"},
+		{name: "fallback defaults unknown abbreviation to UTC", raw: "2023-06-15 15:04:05 XYZ", want: "2023-06-15T15:04:05Z"},
+
+		// Edge cases
+		{name: "empty string returns zero time, no error", raw: "", wantZero: true},
+		{name: "unparseable garbage returns an error", raw: "not a date", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, nil; want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) returned unexpected error: %v", tt.raw, err)
+			}
+
+			if tt.wantZero {
+				if !got.IsZero() {
+					t.Fatalf("Parse(%q) = %v; want zero time", tt.raw, got)
+				}
+				return
+			}
+
+			if gotStr := got.UTC().Format("2006-01-02T15:04:05.999999999Z"); gotStr != tt.want {
+				t.Errorf("Parse(%q) = %s; want %s", tt.raw, gotStr, tt.want)
+			}
+		})
+	}
+}
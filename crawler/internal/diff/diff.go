@@ -0,0 +1,148 @@
+// Package diff computes a Myers diff over line-oriented text, producing the
+// structured hunks MongoStorage.DiffVersions uses to show what changed
+// between two stored page versions.
+package diff
+
+import "fmt"
+
+// Op identifies what kind of change a Hunk represents.
+type Op string
+
+const (
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// Hunk is one line added or removed between two normalized text streams.
+// Path locates it in document order ("line:<n>", n counted in whichever
+// side the line belongs to) so callers can render changes without having
+// to re-run the diff themselves.
+type Hunk struct {
+	Path   string `json:"path" bson:"path"`
+	Op     Op     `json:"op" bson:"op"`
+	Before string `json:"before,omitempty" bson:"before,omitempty"`
+	After  string `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// Lines runs a Myers diff over before and after and returns the changed
+// lines as structured hunks, in document order. Equal lines are omitted —
+// callers only see what actually changed.
+func Lines(before, after []string) []Hunk {
+	edits := myers(before, after)
+
+	hunks := make([]Hunk, 0, len(edits))
+	beforeLine, afterLine := 0, 0
+	for _, e := range edits {
+		switch e.op {
+		case opEqual:
+			beforeLine++
+			afterLine++
+		case opDelete:
+			beforeLine++
+			hunks = append(hunks, Hunk{Path: fmt.Sprintf("line:%d", beforeLine), Op: OpDelete, Before: e.text})
+		case opInsert:
+			afterLine++
+			hunks = append(hunks, Hunk{Path: fmt.Sprintf("line:%d", afterLine), Op: OpInsert, After: e.text})
+		}
+	}
+	return hunks
+}
+
+type editOp int
+
+const (
+	opEqual editOp = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	op   editOp
+	text string
+}
+
+// myers computes the shortest edit script turning a into b using the
+// classic O(ND) greedy algorithm (Myers, 1986), returning it in document
+// order (delete/equal consumed from a, insert taken from b).
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max
+	v := make([]int, 2*max+1)
+	trace := make([][]int, 0, max+1)
+
+	var depth int
+found:
+	for depth = 0; depth <= max; depth++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -depth; k <= depth; k += 2 {
+			var x int
+			if k == -depth || (k != depth && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break found
+			}
+		}
+	}
+
+	// Backtrack through the recorded traces to recover the edit script, then
+	// reverse it into document order.
+	var script []edit
+	x, y := n, m
+	for d := depth; d > 0; d-- {
+		vPrev := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && vPrev[offset+k-1] < vPrev[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vPrev[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			script = append(script, edit{op: opEqual, text: a[x-1]})
+			x--
+			y--
+		}
+
+		if x == prevX {
+			script = append(script, edit{op: opInsert, text: b[y-1]})
+			y--
+		} else {
+			script = append(script, edit{op: opDelete, text: a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		script = append(script, edit{op: opEqual, text: a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(script)-1; i < j; i, j = i+1, j-1 {
+		script[i], script[j] = script[j], script[i]
+	}
+	return script
+}
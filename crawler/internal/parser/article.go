@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Article represents the extracted main content of a page, Readability-style
+type Article struct {
+	Title              string
+	Byline             string
+	SiteName           string
+	Lang               string
+	Excerpt            string
+	ContentHTML        string
+	ContentText        string
+	WordCount          int
+	ReadingTimeMinutes int
+}
+
+var (
+	unlikelyCandidateRegex = regexp.MustCompile(`(?i)comment|meta|footer|footnote|sidebar|sponsor|pagination|share|related|widget|banner|promo|ad-`)
+	positiveCandidateRegex = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+)
+
+// ExtractArticle extracts the main article content from an HTML document using
+// a Readability-style scoring heuristic. Unlike ExtractText, it discards
+// boilerplate (nav, footer, sidebars) so the result is suitable for content
+// length, keyword density, and duplicate-content scoring.
+func (p *HTMLParser) ExtractArticle(htmlContent []byte) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	article := &Article{
+		Title:    p.articleTitle(doc),
+		Byline:   strings.TrimSpace(doc.Find("meta[name='author']").AttrOr("content", "")),
+		SiteName: strings.TrimSpace(doc.Find("meta[property='og:site_name']").AttrOr("content", "")),
+		Lang:     strings.TrimSpace(doc.Find("html").AttrOr("lang", "")),
+	}
+
+	// Remove elements that can never be part of the article body
+	doc.Find("script, style, noscript, iframe, form").Remove()
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		if s.Length() == 0 {
+			return
+		}
+		id, _ := s.Attr("id")
+		class, _ := s.Attr("class")
+		if unlikelyCandidateRegex.MatchString(id + " " + class) {
+			s.Remove()
+		}
+	})
+
+	scores := map[*html.Node]float64{}
+
+	scoreCandidate := func(s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		if len(text) < 25 {
+			return
+		}
+
+		score := 1.0
+		score += float64(strings.Count(text, ","))
+		score += math.Min(float64(len(text)/100), 3)
+
+		id, _ := s.Attr("id")
+		class, _ := s.Attr("class")
+		classAndID := id + " " + class
+		if positiveCandidateRegex.MatchString(classAndID) {
+			score += 25
+		}
+		if unlikelyCandidateRegex.MatchString(classAndID) {
+			score -= 25
+		}
+
+		node := s.Get(0)
+		scores[node] += score
+
+		if parent := node.Parent; parent != nil && parent.Type == html.ElementNode {
+			scores[parent] += score
+
+			if grandparent := parent.Parent; grandparent != nil && grandparent.Type == html.ElementNode {
+				scores[grandparent] += score / 2
+			}
+		}
+	}
+
+	doc.Find("p, pre, td").Each(func(i int, s *goquery.Selection) {
+		scoreCandidate(s)
+	})
+	doc.Find("div").Each(func(i int, s *goquery.Selection) {
+		if s.Children().Filter("div, p, ul, ol, table, section, article").Length() == 0 {
+			scoreCandidate(s)
+		}
+	})
+
+	var topNode *html.Node
+	var topScore float64
+	for node, score := range scores {
+		if topNode == nil || score > topScore {
+			topNode, topScore = node, score
+		}
+	}
+
+	if topNode == nil {
+		text := strings.TrimSpace(doc.Find("body").Text())
+		bodyHTML, _ := doc.Find("body").Html()
+		article.ContentHTML = bodyHTML
+		article.ContentText = text
+		p.finalizeArticle(article, text)
+		return article, nil
+	}
+
+	threshold := math.Max(10, topScore*0.2)
+
+	var buf bytes.Buffer
+	for sibling := firstElementSibling(topNode); sibling != nil; sibling = nextElementSibling(sibling) {
+		if sibling == topNode || scores[sibling] >= threshold || linkDensity(sibling) < 0.25 {
+			_ = html.Render(&buf, sibling)
+		}
+	}
+
+	article.ContentHTML = buf.String()
+
+	contentDoc, err := goquery.NewDocumentFromReader(strings.NewReader(article.ContentHTML))
+	if err == nil {
+		article.ContentText = strings.TrimSpace(contentDoc.Text())
+	}
+
+	p.finalizeArticle(article, article.ContentText)
+
+	return article, nil
+}
+
+// finalizeArticle computes derived fields (excerpt, word count, reading time)
+func (p *HTMLParser) finalizeArticle(article *Article, text string) {
+	words := strings.Fields(text)
+	article.WordCount = len(words)
+	article.ReadingTimeMinutes = int(math.Ceil(float64(article.WordCount) / 200))
+
+	excerptWords := words
+	if len(excerptWords) > 50 {
+		excerptWords = excerptWords[:50]
+	}
+	article.Excerpt = strings.Join(excerptWords, " ")
+}
+
+// articleTitle resolves the best title: og:title, then an <h1> inside an
+// <article> or <main> element, then the first <h1> anywhere on the page,
+// then <title> with the site name suffix stripped
+func (p *HTMLParser) articleTitle(doc *goquery.Document) string {
+	if ogTitle := strings.TrimSpace(doc.Find("meta[property='og:title']").AttrOr("content", "")); ogTitle != "" {
+		return ogTitle
+	}
+
+	if h1 := strings.TrimSpace(doc.Find("article h1, main h1").First().Text()); h1 != "" {
+		return h1
+	}
+
+	if h1 := strings.TrimSpace(doc.Find("h1").First().Text()); h1 != "" {
+		return h1
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	siteName := strings.TrimSpace(doc.Find("meta[property='og:site_name']").AttrOr("content", ""))
+	if siteName != "" {
+		for _, sep := range []string{" | ", " - ", " — ", ": "} {
+			suffix := sep + siteName
+			if strings.HasSuffix(title, suffix) {
+				title = strings.TrimSuffix(title, suffix)
+				break
+			}
+		}
+	}
+
+	return title
+}
+
+// firstElementSibling returns node's first element sibling (inclusive of node
+// itself), walking back to the start of its parent's child list
+func firstElementSibling(node *html.Node) *html.Node {
+	if node.Parent == nil {
+		return node
+	}
+
+	first := node.Parent.FirstChild
+	for first != nil && first.Type != html.ElementNode {
+		first = first.NextSibling
+	}
+	return first
+}
+
+// nextElementSibling returns the next sibling that is an element node
+func nextElementSibling(node *html.Node) *html.Node {
+	n := node.NextSibling
+	for n != nil && n.Type != html.ElementNode {
+		n = n.NextSibling
+	}
+	return n
+}
+
+// linkDensity returns the ratio of anchor text length to total text length
+// for the subtree rooted at node
+func linkDensity(node *html.Node) float64 {
+	totalLen := len(strings.TrimSpace(nodeText(node)))
+	if totalLen == 0 {
+		return 0
+	}
+
+	linkLen := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			linkLen += len(strings.TrimSpace(nodeText(n)))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+
+	return float64(linkLen) / float64(totalLen)
+}
+
+// nodeText collects the concatenated text content of a node's subtree
+func nodeText(node *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// cssURLPattern matches a CSS url() reference, whether introduced by an
+// @import rule or a property value like `background: url(...)`
+var cssURLPattern = regexp.MustCompile(`(?:@import|:).*url\(["']?([^'"\)]+)["']?\)`)
+
+// ExtractCSSURLs finds url(...) references in raw CSS text, at most one per
+// line: on a line where cssURLPattern matches, its greedy ".*" takes the last
+// url(...) after the first @import or ':', so any earlier url(...) on that
+// line is not returned, nor is one on a line without @import or ':'. Each
+// reference is resolved against sourceURL. For an inline <style> block sourceURL is the page's
+// own URL; for an external stylesheet's fetched body it should be the
+// stylesheet's own URL, since relative references inside it are relative to
+// the stylesheet, not the page that links to it.
+func ExtractCSSURLs(css, sourceURL string) []string {
+	base, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil
+	}
+
+	var urls []string
+	for _, line := range strings.Split(css, "\n") {
+		match := cssURLPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		ref, err := url.Parse(strings.TrimSpace(match[1]))
+		if err != nil {
+			continue
+		}
+
+		urls = append(urls, base.ResolveReference(ref).String())
+	}
+
+	return urls
+}
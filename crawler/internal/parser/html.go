@@ -3,6 +3,7 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -36,6 +37,30 @@ type ParsedHTML struct {
 	OpenGraph   OpenGraphData
 	TwitterCard TwitterCardData
 	StructuredData []string
+	Feeds       []FeedLink
+	Icons       []IconLink
+	Assets      []AssetLink
+}
+
+// AssetLink represents a page resource (stylesheet, script, image, media
+// source, iframe, or a CSS url() reference) needed to render the page
+// faithfully when it is archived or replayed later
+type AssetLink struct {
+	Href string
+}
+
+// FeedLink represents a discovered RSS/Atom/JSON feed advertised via <link rel="alternate">
+type FeedLink struct {
+	Href  string
+	Type  string
+	Title string
+}
+
+// IconLink represents a favicon or touch icon advertised via <link rel="icon">
+type IconLink struct {
+	Href  string
+	Sizes string
+	Type  string
 }
 
 // Headings represents all heading levels
@@ -161,9 +186,166 @@ func (p *HTMLParser) Parse(htmlContent []byte, baseURL string) (*ParsedHTML, err
 	// Extract structured data (JSON-LD)
 	parsed.StructuredData = p.extractStructuredData(doc)
 
+	// Extract feed and favicon discovery links
+	parsed.Feeds = p.extractFeeds(doc, baseURL)
+	parsed.Icons = p.extractIcons(doc, baseURL)
+
+	// Extract related resources needed to render the page
+	parsed.Assets = p.extractAssets(doc, baseURL)
+
 	return parsed, nil
 }
 
+// feedTypes maps rel="alternate" type attributes to recognized feed formats
+var feedTypes = map[string]bool{
+	"application/rss+xml":  true,
+	"application/atom+xml": true,
+	"application/feed+json": true,
+	"application/json":     true,
+}
+
+// extractFeeds extracts RSS/Atom/JSON feed discovery links
+func (p *HTMLParser) extractFeeds(doc *goquery.Document, baseURL string) []FeedLink {
+	var feeds []FeedLink
+
+	doc.Find("link[rel='alternate']").Each(func(i int, s *goquery.Selection) {
+		href, exists := s.Attr("href")
+		if !exists || href == "" {
+			return
+		}
+
+		feedType, _ := s.Attr("type")
+		if !feedTypes[strings.ToLower(feedType)] {
+			return
+		}
+
+		title, _ := s.Attr("title")
+
+		feeds = append(feeds, FeedLink{
+			Href:  p.resolveURL(href, baseURL),
+			Type:  feedType,
+			Title: title,
+		})
+	})
+
+	return feeds
+}
+
+// iconRels are the rel values used to advertise favicons and touch icons
+var iconRels = map[string]bool{
+	"icon":             true,
+	"shortcut icon":    true,
+	"apple-touch-icon": true,
+}
+
+// extractIcons extracts favicon and touch icon links
+func (p *HTMLParser) extractIcons(doc *goquery.Document, baseURL string) []IconLink {
+	var icons []IconLink
+
+	doc.Find("link").Each(func(i int, s *goquery.Selection) {
+		rel, exists := s.Attr("rel")
+		if !exists || !iconRels[strings.ToLower(strings.TrimSpace(rel))] {
+			return
+		}
+
+		href, exists := s.Attr("href")
+		if !exists || href == "" {
+			return
+		}
+
+		sizes, _ := s.Attr("sizes")
+		iconType, _ := s.Attr("type")
+
+		icons = append(icons, IconLink{
+			Href:  p.resolveURL(href, baseURL),
+			Sizes: sizes,
+			Type:  iconType,
+		})
+	})
+
+	return icons
+}
+
+// extractAssets collects every related resource a page needs to render
+// faithfully: stylesheets and other <link href> resources, images, scripts,
+// <source> media, iframes, and CSS url() references found in inline <style>
+// blocks or style attributes
+func (p *HTMLParser) extractAssets(doc *goquery.Document, baseURL string) []AssetLink {
+	var assets []AssetLink
+
+	add := func(href string) {
+		href = strings.TrimSpace(href)
+		if href == "" {
+			return
+		}
+		assets = append(assets, AssetLink{Href: p.resolveURL(href, baseURL)})
+	}
+
+	doc.Find("link[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		add(href)
+	})
+
+	doc.Find("img[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		add(src)
+	})
+
+	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		add(src)
+	})
+
+	doc.Find("source").Each(func(i int, s *goquery.Selection) {
+		if src, exists := s.Attr("src"); exists {
+			add(src)
+		}
+		if srcset, exists := s.Attr("srcset"); exists {
+			for _, candidate := range strings.Split(srcset, ",") {
+				fields := strings.Fields(strings.TrimSpace(candidate))
+				if len(fields) > 0 {
+					add(fields[0])
+				}
+			}
+		}
+	})
+
+	doc.Find("iframe[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		add(src)
+	})
+
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		for _, ref := range ExtractCSSURLs(s.Text(), baseURL) {
+			assets = append(assets, AssetLink{Href: ref})
+		}
+	})
+
+	doc.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		style, _ := s.Attr("style")
+		for _, ref := range ExtractCSSURLs(style, baseURL) {
+			assets = append(assets, AssetLink{Href: ref})
+		}
+	})
+
+	return assets
+}
+
+// resolveURL resolves href against baseURL, falling back to the raw href on failure
+func (p *HTMLParser) resolveURL(href, baseURL string) string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return href
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
 // extractHeadings extracts all heading elements
 func (p *HTMLParser) extractHeadings(doc *goquery.Document) Headings {
 	headings := Headings{}
@@ -26,6 +26,7 @@ type ExtractedLink struct {
 	NoFollow bool
 	External bool
 	Type     LinkType
+	Tag      LinkTag
 }
 
 // LinkType represents the type of link
@@ -40,8 +41,21 @@ const (
 	LinkTypeOther    LinkType = "other"
 )
 
-// Extract extracts and normalizes links from parsed HTML
-func (le *LinkExtractor) Extract(links []Link, baseURL string) []ExtractedLink {
+// LinkTag distinguishes a page's primary navigational links, which the
+// crawler follows according to normal depth/domain rules, from the related
+// resources it references (stylesheets, scripts, images, ...), which are
+// always fetched once so an archived page can still be rendered correctly
+type LinkTag string
+
+const (
+	TagPrimary LinkTag = "primary"
+	TagRelated LinkTag = "related"
+)
+
+// Extract extracts and normalizes a page's primary links and related
+// resources into a single unified list, tagged so callers can apply
+// different crawl-scope rules to each
+func (le *LinkExtractor) Extract(links []Link, assets []AssetLink, baseURL string) []ExtractedLink {
 	var extracted []ExtractedLink
 
 	base, err := url.Parse(baseURL)
@@ -56,14 +70,29 @@ func (le *LinkExtractor) Extract(links []Link, baseURL string) []ExtractedLink {
 			continue
 		}
 
-		linkType := le.determineType(normalized, base)
-
 		extracted = append(extracted, ExtractedLink{
 			URL:      normalized,
 			Text:     link.Text,
 			NoFollow: link.NoFollow,
 			External: link.External,
+			Type:     le.determineType(normalized, base),
+			Tag:      TagPrimary,
+		})
+	}
+
+	for _, asset := range assets {
+		normalized := le.normalize(asset.Href, base)
+		if normalized == "" {
+			continue
+		}
+
+		linkType := le.determineType(normalized, base)
+
+		extracted = append(extracted, ExtractedLink{
+			URL:      normalized,
 			Type:     linkType,
+			External: linkType == LinkTypeExternal,
+			Tag:      TagRelated,
 		})
 	}
 
@@ -110,7 +139,10 @@ func (le *LinkExtractor) normalize(href string, base *url.URL) string {
 	return normalized
 }
 
-// determineType determines the type of link
+// determineType determines the type of link. .onion hosts are classified
+// the same way as any other host (internal when they match base.Host,
+// external otherwise) so a seed's onion-ness doesn't need special-casing
+// here; the crawler enforces onion/clearnet scope separately.
 func (le *LinkExtractor) determineType(href string, base *url.URL) LinkType {
 	if strings.HasPrefix(href, "#") {
 		return LinkTypeAnchor
@@ -205,7 +237,10 @@ func (le *LinkExtractor) FilterByScheme(links []ExtractedLink, schemes ...string
 	return filtered
 }
 
-// IsAllowedScheme checks if a URL scheme is allowed for crawling
+// IsAllowedScheme checks if a URL scheme is allowed for crawling. This is a
+// scheme-only check, so .onion hosts are already accepted here the same as
+// any other http(s) target as long as the request is routed through a
+// SOCKS5 proxy (see crawler.CrawlerConfig.ProxyURL).
 func IsAllowedScheme(urlStr string) bool {
 	parsed, err := url.Parse(urlStr)
 	if err != nil {
@@ -215,3 +250,8 @@ func IsAllowedScheme(urlStr string) bool {
 	scheme := strings.ToLower(parsed.Scheme)
 	return scheme == "http" || scheme == "https"
 }
+
+// IsOnionHost reports whether host is a Tor hidden-service (.onion) address
+func IsOnionHost(host string) bool {
+	return strings.HasSuffix(strings.ToLower(host), ".onion")
+}
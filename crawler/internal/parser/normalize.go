@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements are the tags NormalizedTextLines treats as line boundaries
+// when flattening a document into a text stream: a change to markup that
+// doesn't cross one of these stays on the same line.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true,
+	"footer": true, "nav": true, "aside": true, "main": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "table": true, "tr": true, "td": true,
+	"th": true, "blockquote": true, "pre": true, "br": true, "hr": true,
+}
+
+// skipSubtrees are elements whose entire contents are dropped rather than
+// walked, since they carry no page content.
+var skipSubtrees = map[string]bool{
+	"script": true, "style": true, "noscript": true,
+}
+
+// NormalizedTextLines parses htmlContent and flattens it into a sequence of
+// text lines, one per block-element boundary, with scripts and styles
+// removed and internal whitespace collapsed to single spaces. Two HTML
+// documents that differ only in markup (re-indentation, attribute order, an
+// extra wrapping <span>) normalize to the same lines, so DiffVersions can
+// diff them without surfacing those as content changes.
+func (p *HTMLParser) NormalizedTextLines(htmlContent []byte) ([]string, error) {
+	root, err := html.Parse(bytes.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var lines []string
+	var buf strings.Builder
+
+	flush := func() {
+		line := strings.Join(strings.Fields(buf.String()), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+		buf.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && skipSubtrees[n.Data] {
+			return
+		}
+
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+			buf.WriteString(" ")
+			return
+		}
+
+		boundary := n.Type == html.ElementNode && blockElements[n.Data]
+		if boundary {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if boundary {
+			flush()
+		}
+	}
+	walk(root)
+	flush()
+
+	return lines, nil
+}
@@ -0,0 +1,281 @@
+// Package politeness implements a per-host adaptive rate controller: a
+// token bucket per registrable domain that honors robots.txt Crawl-delay,
+// backs off exponentially on 429/503 responses, and recovers once a host
+// looks healthy again.
+package politeness
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+	"golang.org/x/time/rate"
+)
+
+// ControllerConfig holds the defaults every newly-seen host starts with
+type ControllerConfig struct {
+	DefaultRate      float64       // requests per second
+	DefaultBurst     int
+	MaxHosts         int           // LRU cap on tracked hosts
+	BackoffCap       time.Duration // ceiling for the 429/503 backoff delay
+	MinDelay         time.Duration // first backoff step; halving below it clears the delay
+	SuccessesToHalve int           // consecutive 2xx responses before halving the backoff
+}
+
+// Controller tracks a token bucket and backoff state per registrable domain
+type Controller struct {
+	config ControllerConfig
+	mu     sync.Mutex
+	hosts  map[string]*list.Element // host -> element wrapping *hostEntry
+	lru    *list.List
+}
+
+type hostEntry struct {
+	host  string
+	state *hostState
+}
+
+type hostState struct {
+	mu                   sync.Mutex
+	limiter              *rate.Limiter
+	extraDelay           time.Duration
+	consecutiveSuccesses int
+}
+
+// HostStats is a snapshot of one host's politeness state, for /stats
+type HostStats struct {
+	Host                 string        `json:"host"`
+	RatePerSec           float64       `json:"rate_per_sec"`
+	ExtraDelay           time.Duration `json:"extra_delay"`
+	ConsecutiveSuccesses int           `json:"consecutive_successes"`
+}
+
+// NewController creates a new per-host politeness controller
+func NewController(config ControllerConfig) *Controller {
+	if config.DefaultRate == 0 {
+		config.DefaultRate = 1.0
+	}
+	if config.DefaultBurst == 0 {
+		config.DefaultBurst = 1
+	}
+	if config.MaxHosts == 0 {
+		config.MaxHosts = 10000
+	}
+	if config.BackoffCap == 0 {
+		config.BackoffCap = 5 * time.Minute
+	}
+	if config.MinDelay == 0 {
+		config.MinDelay = 1 * time.Second
+	}
+	if config.SuccessesToHalve == 0 {
+		config.SuccessesToHalve = 5
+	}
+
+	return &Controller{
+		config: config,
+		hosts:  make(map[string]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// RegistrableDomain returns host's eTLD+1 (e.g. "blog.example.co.uk" ->
+// "example.co.uk"), so subdomains of the same site share one budget
+func RegistrableDomain(host string) string {
+	host = strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+
+	// IP addresses and single-label hosts fall through here
+	return host
+}
+
+// Acquire blocks until host's token bucket and any backoff delay allow the
+// next request, or ctx is done
+func (c *Controller) Acquire(ctx context.Context, host string) error {
+	hs := c.getOrCreate(host)
+
+	hs.mu.Lock()
+	delay := hs.extraDelay
+	hs.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return hs.limiter.Wait(ctx)
+}
+
+// PeekWait reports how long a caller would currently have to wait before
+// Acquire would return for host, without consuming the reservation. Callers
+// use this to decide whether to block in-process or requeue the work.
+func (c *Controller) PeekWait(host string) time.Duration {
+	hs := c.getOrCreate(host)
+
+	hs.mu.Lock()
+	extraDelay := hs.extraDelay
+	hs.mu.Unlock()
+
+	reservation := hs.limiter.ReserveN(time.Now(), 1)
+	wait := reservation.Delay()
+	reservation.Cancel()
+
+	if extraDelay > wait {
+		return extraDelay
+	}
+	return wait
+}
+
+// SetCrawlDelay applies a robots.txt Crawl-delay to host, overriding its
+// default rate
+func (c *Controller) SetCrawlDelay(host string, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	hs := c.getOrCreate(host)
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hs.limiter.SetLimit(rate.Limit(1 / delay.Seconds()))
+	hs.limiter.SetBurst(1)
+}
+
+// ReportResponse adjusts host's backoff delay based on the outcome of a
+// request: 429/503 doubles the delay (or jumps straight to retryAfter when
+// the server supplied one), capped at BackoffCap; a run of SuccessesToHalve
+// 2xx responses halves it, and once halving takes it below MinDelay it is
+// cleared to zero.
+func (c *Controller) ReportResponse(host string, statusCode int, retryAfter time.Duration) {
+	hs := c.getOrCreate(host)
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	switch {
+	case statusCode == 429 || statusCode == 503:
+		hs.consecutiveSuccesses = 0
+
+		switch {
+		case retryAfter > 0:
+			hs.extraDelay = retryAfter
+		case hs.extraDelay == 0:
+			hs.extraDelay = c.config.MinDelay
+		default:
+			hs.extraDelay *= 2
+		}
+
+		if hs.extraDelay > c.config.BackoffCap {
+			hs.extraDelay = c.config.BackoffCap
+		}
+
+	case statusCode >= 200 && statusCode < 300:
+		if hs.extraDelay == 0 {
+			return
+		}
+
+		hs.consecutiveSuccesses++
+		if hs.consecutiveSuccesses < c.config.SuccessesToHalve {
+			return
+		}
+
+		hs.consecutiveSuccesses = 0
+		hs.extraDelay /= 2
+		if hs.extraDelay < c.config.MinDelay {
+			hs.extraDelay = 0
+		}
+	}
+}
+
+// Stats returns a snapshot of every currently-tracked host
+func (c *Controller) Stats() []HostStats {
+	c.mu.Lock()
+	elements := make([]*list.Element, 0, len(c.hosts))
+	for _, el := range c.hosts {
+		elements = append(elements, el)
+	}
+	c.mu.Unlock()
+
+	stats := make([]HostStats, 0, len(elements))
+	for _, el := range elements {
+		entry := el.Value.(*hostEntry)
+		entry.state.mu.Lock()
+		stats = append(stats, HostStats{
+			Host:                 entry.host,
+			RatePerSec:           float64(entry.state.limiter.Limit()),
+			ExtraDelay:           entry.state.extraDelay,
+			ConsecutiveSuccesses: entry.state.consecutiveSuccesses,
+		})
+		entry.state.mu.Unlock()
+	}
+
+	return stats
+}
+
+// getOrCreate returns host's state, creating it with the configured
+// defaults if this is the first time host is seen, and evicting the least
+// recently used host once MaxHosts is exceeded
+func (c *Controller) getOrCreate(host string) *hostState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.hosts[host]; ok {
+		c.lru.MoveToFront(el)
+		return el.Value.(*hostEntry).state
+	}
+
+	state := &hostState{
+		limiter: rate.NewLimiter(rate.Limit(c.config.DefaultRate), c.config.DefaultBurst),
+	}
+	el := c.lru.PushFront(&hostEntry{host: host, state: state})
+	c.hosts[host] = el
+
+	if len(c.hosts) > c.config.MaxHosts {
+		oldest := c.lru.Back()
+		if oldest != nil {
+			c.lru.Remove(oldest)
+			delete(c.hosts, oldest.Value.(*hostEntry).host)
+		}
+	}
+
+	return state
+}
+
+// ParseRetryAfter parses an HTTP Retry-After header value, which is either
+// a number of seconds or an HTTP-date. It returns 0 if value is empty or
+// unparseable.
+func ParseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if when, err := time.Parse(time.RFC1123, value); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
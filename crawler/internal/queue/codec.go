@@ -0,0 +1,34 @@
+package queue
+
+import "encoding/json"
+
+// Codec converts a value to and from its Kafka wire representation.
+// KafkaConfig.Codec selects which implementation PublishCrawlJob,
+// PublishCrawlJobBatch, and ConsumeCrawlJobs use, so CrawlMessage's
+// encoding can be swapped (or evolved under a schema registry) without
+// touching KafkaQueue's publish/consume logic.
+type Codec interface {
+	// Encode serializes v into its wire bytes.
+	Encode(v any) ([]byte, error)
+	// Decode deserializes data into v, which must be a pointer.
+	Decode(data []byte, v any) error
+	// ContentType identifies the encoding, used as the "content-type" Kafka
+	// header so a consumer can tell which codec produced a given message.
+	ContentType() string
+}
+
+// JSONCodec encodes/decodes with encoding/json. It's KafkaQueue's default
+// codec, preserving the wire format existing consumers already expect.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
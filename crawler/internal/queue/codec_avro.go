@@ -0,0 +1,204 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+)
+
+// confluentMagicByte is the leading byte of the Confluent Schema Registry
+// wire format: magic byte, then a big-endian 4-byte schema ID, then the
+// Avro-encoded body.
+const confluentMagicByte byte = 0x0
+
+// AvroCodec encodes/decodes values as Avro, wrapped in the Confluent wire
+// format so downstream consumers built on the Confluent ecosystem (Flink,
+// ksqlDB, and similar) can resolve the schema straight from Schema Registry
+// instead of needing it out-of-band.
+//
+// Schema IDs are cached per subject after the first register-or-lookup
+// call, and schemas fetched by ID are cached the same way, since Schema
+// Registry's HTTP API would otherwise be a round trip on every publish and
+// decode.
+type AvroCodec struct {
+	// RegistryURL is the Schema Registry base URL, e.g. "http://localhost:8081".
+	RegistryURL string
+	// Subject identifies the schema in the registry, e.g. "crawl-jobs-value".
+	Subject string
+	// Schema is the Avro schema (JSON) registered under Subject and used to
+	// encode outgoing values.
+	Schema string
+
+	httpClient *http.Client
+
+	mu           sync.RWMutex
+	encodeID     int
+	encodeIDSet  bool
+	encodeSchema avro.Schema
+	byID         map[int]avro.Schema
+}
+
+// NewAvroCodec returns an AvroCodec that registers/resolves schema against
+// registryURL under subject, encoding outgoing values with schema.
+func NewAvroCodec(registryURL, subject, schema string) *AvroCodec {
+	return &AvroCodec{
+		RegistryURL: strings.TrimRight(registryURL, "/"),
+		Subject:     subject,
+		Schema:      schema,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		byID:        make(map[int]avro.Schema),
+	}
+}
+
+func (c *AvroCodec) ContentType() string {
+	return "application/vnd.schemaregistry.v1+json"
+}
+
+// Encode resolves (registering if necessary) the schema ID for c.Subject,
+// Avro-encodes v against c.Schema, and prepends the Confluent wire header.
+func (c *AvroCodec) Encode(v any) ([]byte, error) {
+	id, schema, err := c.resolveEncodeSchema()
+	if err != nil {
+		return nil, fmt.Errorf("avro codec: resolve schema for subject %q: %w", c.Subject, err)
+	}
+
+	body, err := avro.Marshal(schema, v)
+	if err != nil {
+		return nil, fmt.Errorf("avro codec: marshal: %w", err)
+	}
+
+	buf := make([]byte, 5+len(body))
+	buf[0] = confluentMagicByte
+	binary.BigEndian.PutUint32(buf[1:5], uint32(id))
+	copy(buf[5:], body)
+	return buf, nil
+}
+
+// Decode reads the Confluent wire header off data, looks up (and caches)
+// the schema it names, and Avro-decodes the remaining bytes into v.
+func (c *AvroCodec) Decode(data []byte, v any) error {
+	if len(data) < 5 || data[0] != confluentMagicByte {
+		return fmt.Errorf("avro codec: missing Confluent wire-format header")
+	}
+	id := int(binary.BigEndian.Uint32(data[1:5]))
+
+	schema, err := c.schemaByID(id)
+	if err != nil {
+		return fmt.Errorf("avro codec: resolve schema id %d: %w", id, err)
+	}
+
+	return avro.Unmarshal(schema, data[5:], v)
+}
+
+func (c *AvroCodec) resolveEncodeSchema() (int, avro.Schema, error) {
+	c.mu.RLock()
+	if c.encodeIDSet {
+		id, schema := c.encodeID, c.encodeSchema
+		c.mu.RUnlock()
+		return id, schema, nil
+	}
+	c.mu.RUnlock()
+
+	schema, err := avro.Parse(c.Schema)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parse schema: %w", err)
+	}
+
+	id, err := c.registerSchema()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c.mu.Lock()
+	c.encodeID, c.encodeSchema, c.encodeIDSet = id, schema, true
+	c.byID[id] = schema
+	c.mu.Unlock()
+
+	return id, schema, nil
+}
+
+func (c *AvroCodec) schemaByID(id int) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	fetched, err := c.fetchSchemaByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.byID[id] = fetched
+	c.mu.Unlock()
+
+	return fetched, nil
+}
+
+// registerSchema registers c.Schema under c.Subject, returning the schema ID
+// Schema Registry assigns it (or its existing ID, if that exact schema was
+// already registered for the subject).
+func (c *AvroCodec) registerSchema() (int, error) {
+	reqBody, err := json.Marshal(map[string]string{"schema": c.Schema})
+	if err != nil {
+		return 0, fmt.Errorf("marshal register request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/subjects/%s/versions", c.RegistryURL, c.Subject)
+	resp, err := c.httpClient.Post(url, "application/vnd.schemaregistry.v1+json", bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, fmt.Errorf("register schema: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("register schema: registry returned %d: %s", resp.StatusCode, body)
+	}
+
+	var parsed struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return 0, fmt.Errorf("decode register response: %w", err)
+	}
+	return parsed.ID, nil
+}
+
+// fetchSchemaByID looks up the Avro schema Schema Registry has under id.
+func (c *AvroCodec) fetchSchemaByID(id int) (avro.Schema, error) {
+	url := fmt.Sprintf("%s/schemas/ids/%d", c.RegistryURL, id)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch schema: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("fetch schema: registry returned %d: %s", resp.StatusCode, body)
+	}
+
+	var parsed struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("decode schema response: %w", err)
+	}
+
+	schema, err := avro.Parse(parsed.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	return schema, nil
+}
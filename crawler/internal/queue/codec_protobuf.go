@@ -0,0 +1,34 @@
+package queue
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufCodec encodes/decodes values that implement proto.Message.
+// CrawlMessage itself is a plain JSON-tagged struct rather than a generated
+// Protobuf type, so a caller that wants this codec must publish/consume its
+// own proto.Message wrapper; anything else is rejected rather than silently
+// falling back to JSON.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Encode(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (ProtobufCodec) Decode(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func (ProtobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
@@ -3,19 +3,117 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
+
+	"github.com/seo-platform/crawler/internal/politeness"
 )
 
+// defaultPriorityWeights is the weighted round-robin schedule ConsumeCrawlJobs
+// uses to drain priority lanes, highest first, so a burst of low-priority
+// discovered links can't starve a newly-submitted high-priority seed.
+var defaultPriorityWeights = []int{8, 4, 2, 1}
+
+// retryTier is one stage of the tiered retry backoff a failed handler call
+// escalates through: "<Topic>.retry.5s", then ".30s", then ".5m". A message
+// that's still failing after it's been through every tier is routed to
+// "<Topic>.dlq" instead of escalating further.
+type retryTier struct {
+	suffix string
+	delay  time.Duration
+}
+
+var retryTiers = []retryTier{
+	{suffix: "5s", delay: 5 * time.Second},
+	{suffix: "30s", delay: 30 * time.Second},
+	{suffix: "5m", delay: 5 * time.Minute},
+}
+
+// Header keys a retried CrawlMessage carries on its Kafka headers, so its
+// attempt count and failure history survive the round trip back through the
+// main topic without changing CrawlMessage's JSON body.
+const (
+	headerRetryAttempts    = "x-retry-attempts"
+	headerRetryFirstFailed = "x-retry-first-failed-at"
+	headerRetryLastError   = "x-retry-last-error"
+)
+
+// RetryEnvelope wraps a CrawlMessage that failed its handler for transit
+// through a retry or DLQ topic, preserving enough failure history for an
+// operator (or ConsumeDLQ) to inspect and replay it.
+type RetryEnvelope struct {
+	OriginalTopic string       `json:"original_topic"`
+	Message       CrawlMessage `json:"message"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"last_error"`
+	FirstFailedAt time.Time    `json:"first_failed_at"`
+}
+
+// retryTopic returns the Kafka topic backing tier's retry queue for base.
+func retryTopic(base, suffix string) string {
+	return fmt.Sprintf("%s.retry.%s", base, suffix)
+}
+
+// dlqTopic returns the dead-letter topic for base.
+func dlqTopic(base string) string {
+	return base + ".dlq"
+}
+
+// ErrBufferFull is returned by PublishCrawlJob/PublishCrawlJobBatch in async
+// mode when the buffered-records channel is saturated and
+// KafkaConfig.BlockOnBufferFull is false.
+var ErrBufferFull = errors.New("kafka queue: async publish buffer full")
+
+// ErrQueueClosed is returned by PublishCrawlJob/PublishCrawlJobBatch in
+// async mode once Close has been called, instead of sending on the
+// already-closed buffer channel.
+var ErrQueueClosed = errors.New("kafka queue: closed")
+
+// DeliveryReport carries the outcome of one async-mode publish, delivered on
+// KafkaQueue.DeliveryReports() once the underlying kafka.Writer's Completion
+// callback fires.
+type DeliveryReport struct {
+	Message   *CrawlMessage
+	Partition int
+	Offset    int64
+	Error     error
+}
+
 // KafkaQueue manages Kafka message queue operations
 type KafkaQueue struct {
-	writer *kafka.Writer
-	reader *kafka.Reader
-	logger *zap.Logger
-	config KafkaConfig
+	writer       *kafka.Writer
+	readers      []*kafka.Reader // one per priority lane, index 0 is highest priority
+	retryReaders []*kafka.Reader // one per retryTiers entry, same order
+	dlqReader    *kafka.Reader
+	logger       *zap.Logger
+	config       KafkaConfig
+
+	// Async-mode publishing. asyncWriter is a dedicated kafka.Writer with
+	// Async: true, whose Completion callback is the only source of
+	// deliveryReports. buffer decouples PublishCrawlJob's caller from the
+	// writer; lifecycleCtx (not any caller's ctx) is what actually bounds
+	// asyncWriter.WriteMessages, since callers routinely cancel per-request
+	// contexts that must not abort an in-flight async produce.
+	asyncWriter     *kafka.Writer
+	buffer          chan kafka.Message
+	deliveryReports chan DeliveryReport
+	lifecycleCtx    context.Context
+	lifecycleCancel context.CancelFunc
+	asyncWG         sync.WaitGroup
+
+	// closeMu guards closed: Close takes the write lock before closing
+	// buffer, so any enqueueAsync holding the read lock has either already
+	// sent on buffer or observed closed and returned, never both.
+	closeMu sync.RWMutex
+	closed  bool
 }
 
 // KafkaConfig holds Kafka configuration
@@ -25,10 +123,102 @@ type KafkaConfig struct {
 	ConsumerGroup string
 	BatchSize     int
 	BatchTimeout  time.Duration
+	// PriorityWeights sets the number of priority lanes (len(PriorityWeights))
+	// and how often ConsumeCrawlJobs drains each relative to the others.
+	// Lane 0 is the highest-priority topic ("<Topic>-p0"); defaults to
+	// defaultPriorityWeights.
+	PriorityWeights []int
+	// MaxRetryAttempts caps how many times a message that keeps failing its
+	// handler cycles through the retry tiers before it's routed to the DLQ
+	// topic instead. Defaults to len(retryTiers) (one attempt per tier).
+	MaxRetryAttempts int
+
+	// Async switches PublishCrawlJob/PublishCrawlJobBatch to buffered,
+	// non-blocking-by-default publishing: see DeliveryReports.
+	Async bool
+	// MaxBufferedRecords bounds the async publish buffer. Defaults to 1000.
+	MaxBufferedRecords int
+	// AsyncWorkers is how many goroutines drain the async buffer into the
+	// underlying kafka.Writer. Defaults to 4.
+	AsyncWorkers int
+	// BlockOnBufferFull, when true, makes PublishCrawlJob block (respecting
+	// the caller's ctx) instead of returning ErrBufferFull when the async
+	// buffer is saturated.
+	BlockOnBufferFull bool
+	// AsyncFlushTimeout bounds how long Close waits for the async buffer and
+	// any in-flight produce calls to drain before giving up. Defaults to 10s.
+	AsyncFlushTimeout time.Duration
+
+	// Codec encodes/decodes CrawlMessage on the wire for PublishCrawlJob,
+	// PublishCrawlJobBatch, and ConsumeCrawlJobs. Defaults to JSONCodec, the
+	// format existing consumers already expect.
+	Codec Codec
+}
+
+func (c KafkaConfig) priorityWeights() []int {
+	if len(c.PriorityWeights) == 0 {
+		return defaultPriorityWeights
+	}
+	return c.PriorityWeights
+}
+
+func (c KafkaConfig) maxRetryAttempts() int {
+	if c.MaxRetryAttempts <= 0 {
+		return len(retryTiers)
+	}
+	return c.MaxRetryAttempts
+}
+
+func (c KafkaConfig) maxBufferedRecords() int {
+	if c.MaxBufferedRecords <= 0 {
+		return 1000
+	}
+	return c.MaxBufferedRecords
+}
+
+func (c KafkaConfig) asyncWorkers() int {
+	if c.AsyncWorkers <= 0 {
+		return 4
+	}
+	return c.AsyncWorkers
+}
+
+func (c KafkaConfig) asyncFlushTimeout() time.Duration {
+	if c.AsyncFlushTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return c.AsyncFlushTimeout
+}
+
+func (c KafkaConfig) codec() Codec {
+	if c.Codec == nil {
+		return JSONCodec{}
+	}
+	return c.Codec
+}
+
+// hostBalancer routes messages to Kafka partitions by a stable FNV-1a hash
+// of the message key (the job's registrable domain), so every URL for one
+// host lands on the same partition and is consumed by exactly one worker.
+// That's a precondition for internal/politeness.Controller to actually
+// rate-limit a host, since its state lives in-process on whichever worker
+// owns it.
+type hostBalancer struct{}
+
+func (hostBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	h := fnv.New32a()
+	h.Write(msg.Key)
+	return partitions[h.Sum32()%uint32(len(partitions))]
 }
 
 // NewKafkaQueue creates a new Kafka queue manager
-func NewKafkaQueue(config KafkaConfig, logger *zap.Logger) *KafkaQueue {
+func NewKafkaQueue(config KafkaConfig, logger *zap.Logger) (*KafkaQueue, error) {
+	if _, ok := config.codec().(ProtobufCodec); ok {
+		return nil, fmt.Errorf("kafka queue: ProtobufCodec cannot encode CrawlMessage, which is a " +
+			"plain JSON-tagged struct and doesn't implement proto.Message; use JSONCodec, AvroCodec, " +
+			"or a Codec backed by a generated CrawlMessage protobuf type")
+	}
+
 	if config.BatchSize == 0 {
 		config.BatchSize = 100
 	}
@@ -36,10 +226,12 @@ func NewKafkaQueue(config KafkaConfig, logger *zap.Logger) *KafkaQueue {
 		config.BatchTimeout = 1 * time.Second
 	}
 
+	// Writer.Topic is left unset: every message carries its own priority-lane
+	// Topic, since jobs fan out across "<Topic>-p0".."<Topic>-pN" rather than
+	// a single topic.
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(config.Brokers...),
-		Topic:        config.Topic,
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     hostBalancer{},
 		BatchSize:    config.BatchSize,
 		BatchTimeout: config.BatchTimeout,
 		Compression:  kafka.Snappy,
@@ -47,14 +239,43 @@ func NewKafkaQueue(config KafkaConfig, logger *zap.Logger) *KafkaQueue {
 		Async:        false,
 	}
 
-	var reader *kafka.Reader
+	weights := config.priorityWeights()
+	var readers []*kafka.Reader
+	var retryReaders []*kafka.Reader
+	var dlqReader *kafka.Reader
 	if config.ConsumerGroup != "" {
-		reader = kafka.NewReader(kafka.ReaderConfig{
+		readers = make([]*kafka.Reader, len(weights))
+		for i := range weights {
+			readers[i] = kafka.NewReader(kafka.ReaderConfig{
+				Brokers:        config.Brokers,
+				Topic:          topicForLane(config.Topic, i),
+				GroupID:        config.ConsumerGroup,
+				MinBytes:       10e3, // 10KB
+				MaxBytes:       10e6, // 10MB
+				CommitInterval: time.Second,
+				StartOffset:    kafka.LastOffset,
+			})
+		}
+
+		retryReaders = make([]*kafka.Reader, len(retryTiers))
+		for i, tier := range retryTiers {
+			retryReaders[i] = kafka.NewReader(kafka.ReaderConfig{
+				Brokers:        config.Brokers,
+				Topic:          retryTopic(config.Topic, tier.suffix),
+				GroupID:        config.ConsumerGroup,
+				MinBytes:       10e3,
+				MaxBytes:       10e6,
+				CommitInterval: time.Second,
+				StartOffset:    kafka.LastOffset,
+			})
+		}
+
+		dlqReader = kafka.NewReader(kafka.ReaderConfig{
 			Brokers:        config.Brokers,
-			Topic:          config.Topic,
+			Topic:          dlqTopic(config.Topic),
 			GroupID:        config.ConsumerGroup,
-			MinBytes:       10e3, // 10KB
-			MaxBytes:       10e6, // 10MB
+			MinBytes:       10e3,
+			MaxBytes:       10e6,
 			CommitInterval: time.Second,
 			StartOffset:    kafka.LastOffset,
 		})
@@ -63,40 +284,211 @@ func NewKafkaQueue(config KafkaConfig, logger *zap.Logger) *KafkaQueue {
 	logger.Info("created Kafka queue",
 		zap.Strings("brokers", config.Brokers),
 		zap.String("topic", config.Topic),
+		zap.Int("priority_lanes", len(weights)),
+		zap.Int("retry_tiers", len(retryTiers)),
+		zap.Bool("async", config.Async),
 	)
 
-	return &KafkaQueue{
-		writer: writer,
-		reader: reader,
-		logger: logger,
-		config: config,
+	lifecycleCtx, lifecycleCancel := context.WithCancel(context.Background())
+
+	kq := &KafkaQueue{
+		writer:          writer,
+		readers:         readers,
+		retryReaders:    retryReaders,
+		dlqReader:       dlqReader,
+		logger:          logger,
+		config:          config,
+		lifecycleCtx:    lifecycleCtx,
+		lifecycleCancel: lifecycleCancel,
+	}
+
+	if config.Async {
+		kq.buffer = make(chan kafka.Message, config.maxBufferedRecords())
+		kq.deliveryReports = make(chan DeliveryReport, config.maxBufferedRecords())
+		kq.startAsyncWorkers()
+	}
+
+	return kq, nil
+}
+
+// startAsyncWorkers creates the dedicated async kafka.Writer and launches
+// the worker pool that drains kq.buffer into it. Every produce goes through
+// kq.lifecycleCtx, never a caller's ctx, so a cancelled per-request context
+// can't drop an in-flight async batch.
+func (kq *KafkaQueue) startAsyncWorkers() {
+	kq.asyncWriter = &kafka.Writer{
+		Addr:         kafka.TCP(kq.config.Brokers...),
+		Balancer:     hostBalancer{},
+		BatchSize:    kq.config.BatchSize,
+		BatchTimeout: kq.config.BatchTimeout,
+		Compression:  kafka.Snappy,
+		RequiredAcks: kafka.RequireOne,
+		Async:        true,
+		Completion: func(messages []kafka.Message, err error) {
+			for _, m := range messages {
+				report := DeliveryReport{Partition: m.Partition, Offset: m.Offset, Error: err}
+
+				var crawlMsg CrawlMessage
+				if decodeErr := kq.config.codec().Decode(m.Value, &crawlMsg); decodeErr == nil {
+					report.Message = &crawlMsg
+				}
+
+				select {
+				case kq.deliveryReports <- report:
+				default:
+					kq.logger.Warn("dropped delivery report, DeliveryReports channel full")
+				}
+			}
+		},
+	}
+
+	kq.asyncWG.Add(kq.config.asyncWorkers())
+	for i := 0; i < kq.config.asyncWorkers(); i++ {
+		go func() {
+			defer kq.asyncWG.Done()
+			for {
+				select {
+				case msg, ok := <-kq.buffer:
+					if !ok {
+						return
+					}
+					if err := kq.asyncWriter.WriteMessages(kq.lifecycleCtx, msg); err != nil {
+						kq.logger.Error("async publish failed", zap.Error(err))
+					}
+				case <-kq.lifecycleCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// DeliveryReports returns the channel async-mode publish outcomes arrive on.
+// Only meaningful when KafkaConfig.Async is true; callers that enable async
+// mode must drain it or risk the "channel full" drops logged by
+// startAsyncWorkers' Completion callback.
+func (kq *KafkaQueue) DeliveryReports() <-chan DeliveryReport {
+	return kq.deliveryReports
+}
+
+// enqueueAsync hands msg to the async buffer, blocking on ctx if
+// BlockOnBufferFull is set, or returning ErrBufferFull immediately otherwise.
+// It returns ErrQueueClosed instead of sending once Close has run, since
+// Close closes buffer and a send on a closed channel panics.
+func (kq *KafkaQueue) enqueueAsync(ctx context.Context, msg kafka.Message) error {
+	kq.closeMu.RLock()
+	defer kq.closeMu.RUnlock()
+	if kq.closed {
+		return ErrQueueClosed
+	}
+
+	if kq.config.BlockOnBufferFull {
+		select {
+		case kq.buffer <- msg:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case kq.buffer <- msg:
+		return nil
+	default:
+		return ErrBufferFull
 	}
 }
 
 // CrawlMessage represents a crawl job message
 type CrawlMessage struct {
-	JobID     int64             `json:"job_id"`
-	URL       string            `json:"url"`
-	Depth     int               `json:"depth"`
-	MaxDepth  int               `json:"max_depth"`
-	Priority  int               `json:"priority"`
+	JobID    int64  `json:"job_id"`
+	URL      string `json:"url"`
+	Depth    int    `json:"depth"`
+	MaxDepth int    `json:"max_depth"`
+	// Priority ranks how urgently this job should be drained relative to
+	// others: higher is more urgent. It's clamped into a fixed number of
+	// Kafka priority lanes (see KafkaConfig.PriorityWeights) and decays
+	// toward 0 as child URLs are discovered at increasing depth.
+	Priority int  `json:"priority"`
+	Related  bool `json:"related,omitempty"`
+	// NotBefore, when set, means this job was requeued to respect a
+	// per-host politeness delay and must not be dispatched before this time.
+	NotBefore time.Time         `json:"not_before,omitempty"`
 	Metadata  map[string]string `json:"metadata,omitempty"`
 	CreatedAt time.Time         `json:"created_at"`
 }
 
-// PublishCrawlJob publishes a crawl job to Kafka
-func (kq *KafkaQueue) PublishCrawlJob(ctx context.Context, message *CrawlMessage) error {
+// topicForLane returns the Kafka topic backing priority lane i (0 is
+// highest priority) of base.
+func topicForLane(base string, i int) string {
+	return fmt.Sprintf("%s-p%d", base, i)
+}
+
+// laneForPriority maps a CrawlMessage.Priority onto one of levels priority
+// lanes: the highest priority value (clamped to levels-1) lands on lane 0,
+// decaying down to the least-urgent lane as priority falls toward 0.
+func laneForPriority(priority, levels int) int {
+	if priority < 0 {
+		priority = 0
+	}
+	if priority > levels-1 {
+		priority = levels - 1
+	}
+	return levels - 1 - priority
+}
+
+// registrableHost returns message.URL's registrable domain, used as the
+// partitioning key so a host's jobs all land on one partition.
+func registrableHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return politeness.RegistrableDomain(parsed.Host)
+}
+
+// headerContentType names the codec a message's Value was encoded with, so a
+// consumer reading across a codec migration can tell JSON and Avro/Protobuf
+// records apart.
+const headerContentType = "content-type"
+
+func (kq *KafkaQueue) toKafkaMessage(message *CrawlMessage) (kafka.Message, error) {
 	message.CreatedAt = time.Now()
 
-	data, err := json.Marshal(message)
+	codec := kq.config.codec()
+	data, err := codec.Encode(message)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return kafka.Message{}, fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	msg := kafka.Message{
-		Key:   []byte(message.URL),
-		Value: data,
-		Time:  time.Now(),
+	lane := laneForPriority(message.Priority, len(kq.config.priorityWeights()))
+
+	return kafka.Message{
+		Topic:   topicForLane(kq.config.Topic, lane),
+		Key:     []byte(registrableHost(message.URL)),
+		Value:   data,
+		Time:    time.Now(),
+		Headers: []kafka.Header{{Key: headerContentType, Value: []byte(codec.ContentType())}},
+	}, nil
+}
+
+// PublishCrawlJob publishes a crawl job to Kafka
+func (kq *KafkaQueue) PublishCrawlJob(ctx context.Context, message *CrawlMessage) error {
+	msg, err := kq.toKafkaMessage(message)
+	if err != nil {
+		return err
+	}
+
+	if kq.config.Async {
+		if err := kq.enqueueAsync(ctx, msg); err != nil {
+			return err
+		}
+		kq.logger.Debug("buffered crawl job for async publish",
+			zap.Int64("job_id", message.JobID),
+			zap.String("url", message.URL),
+			zap.String("topic", msg.Topic),
+		)
+		return nil
 	}
 
 	if err := kq.writer.WriteMessages(ctx, msg); err != nil {
@@ -106,6 +498,7 @@ func (kq *KafkaQueue) PublishCrawlJob(ctx context.Context, message *CrawlMessage
 	kq.logger.Info("published crawl job",
 		zap.Int64("job_id", message.JobID),
 		zap.String("url", message.URL),
+		zap.String("topic", msg.Topic),
 	)
 
 	return nil
@@ -119,18 +512,23 @@ func (kq *KafkaQueue) PublishCrawlJobBatch(ctx context.Context, messages []*Craw
 
 	kafkaMessages := make([]kafka.Message, len(messages))
 	for i, msg := range messages {
-		msg.CreatedAt = time.Now()
-
-		data, err := json.Marshal(msg)
+		kafkaMsg, err := kq.toKafkaMessage(msg)
 		if err != nil {
 			return fmt.Errorf("failed to marshal message %d: %w", i, err)
 		}
+		kafkaMessages[i] = kafkaMsg
+	}
 
-		kafkaMessages[i] = kafka.Message{
-			Key:   []byte(msg.URL),
-			Value: data,
-			Time:  time.Now(),
+	if kq.config.Async {
+		for i, kafkaMsg := range kafkaMessages {
+			if err := kq.enqueueAsync(ctx, kafkaMsg); err != nil {
+				return fmt.Errorf("failed to buffer message %d: %w", i, err)
+			}
 		}
+		kq.logger.Debug("buffered crawl job batch for async publish",
+			zap.Int("count", len(messages)),
+		)
+		return nil
 	}
 
 	if err := kq.writer.WriteMessages(ctx, kafkaMessages...); err != nil {
@@ -144,13 +542,61 @@ func (kq *KafkaQueue) PublishCrawlJobBatch(ctx context.Context, messages []*Craw
 	return nil
 }
 
-// ConsumeCrawlJobs consumes crawl jobs from Kafka
+// buildDrainSchedule returns a slice of priority-lane indices, length
+// sum(weights), interleaved by max-min fairness so high-weight lanes are
+// visited more often without letting any lane monopolize long runs (e.g.
+// weights {8,4,2,1} spread lane 0 out across the schedule rather than
+// running it 8 times before touching lane 1).
+func buildDrainSchedule(weights []int) []int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	counts := make([]int, len(weights))
+	schedule := make([]int, 0, total)
+	for len(schedule) < total {
+		best := -1
+		bestRatio := -1.0
+		for i, w := range weights {
+			if w == 0 {
+				continue
+			}
+			ratio := float64(w) / float64(counts[i]+1)
+			if ratio > bestRatio {
+				bestRatio = ratio
+				best = i
+			}
+		}
+		schedule = append(schedule, best)
+		counts[best]++
+	}
+	return schedule
+}
+
+// lanePollTimeout bounds how long ConsumeCrawlJobs waits on one lane before
+// moving on to the next lane in the drain schedule.
+const lanePollTimeout = 200 * time.Millisecond
+
+// ConsumeCrawlJobs consumes crawl jobs from Kafka, draining priority lanes
+// in weighted round-robin order (see buildDrainSchedule) so low-priority
+// backlog can't starve high-priority work.
 func (kq *KafkaQueue) ConsumeCrawlJobs(ctx context.Context, handler func(*CrawlMessage) error) error {
-	if kq.reader == nil {
+	if len(kq.readers) == 0 {
 		return fmt.Errorf("no reader configured")
 	}
 
-	kq.logger.Info("starting to consume crawl jobs")
+	kq.logger.Info("starting to consume crawl jobs", zap.Int("priority_lanes", len(kq.readers)))
+
+	for i, tier := range retryTiers {
+		if i >= len(kq.retryReaders) {
+			break
+		}
+		go kq.consumeRetryTier(ctx, kq.retryReaders[i], tier)
+	}
+
+	schedule := buildDrainSchedule(kq.config.priorityWeights())
+	pos := 0
 
 	for {
 		select {
@@ -158,46 +604,346 @@ func (kq *KafkaQueue) ConsumeCrawlJobs(ctx context.Context, handler func(*CrawlM
 			kq.logger.Info("stopping crawl job consumer")
 			return ctx.Err()
 		default:
-			msg, err := kq.reader.FetchMessage(ctx)
-			if err != nil {
-				kq.logger.Error("failed to fetch message", zap.Error(err))
+		}
+
+		reader := kq.readers[schedule[pos]]
+		pos = (pos + 1) % len(schedule)
+
+		pollCtx, cancel := context.WithTimeout(ctx, lanePollTimeout)
+		msg, err := reader.FetchMessage(pollCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				// Nothing ready on this lane; move on to the next one.
 				continue
 			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			kq.logger.Error("failed to fetch message", zap.Error(err))
+			continue
+		}
 
-			var crawlMsg CrawlMessage
-			if err := json.Unmarshal(msg.Value, &crawlMsg); err != nil {
-				kq.logger.Error("failed to unmarshal message",
-					zap.Error(err),
-					zap.ByteString("value", msg.Value),
-				)
-				// Commit anyway to avoid blocking
-				if err := kq.reader.CommitMessages(ctx, msg); err != nil {
-					kq.logger.Error("failed to commit message", zap.Error(err))
-				}
-				continue
+		var crawlMsg CrawlMessage
+		if err := kq.config.codec().Decode(msg.Value, &crawlMsg); err != nil {
+			kq.logger.Error("failed to unmarshal message",
+				zap.Error(err),
+				zap.ByteString("value", msg.Value),
+			)
+			// Commit anyway to avoid blocking
+			if err := reader.CommitMessages(ctx, msg); err != nil {
+				kq.logger.Error("failed to commit message", zap.Error(err))
 			}
+			continue
+		}
+
+		// Process the message
+		if err := handler(&crawlMsg); err != nil {
+			kq.logger.Error("failed to handle message",
+				zap.Error(err),
+				zap.String("url", crawlMsg.URL),
+			)
 
-			// Process the message
-			if err := handler(&crawlMsg); err != nil {
-				kq.logger.Error("failed to handle message",
-					zap.Error(err),
+			// Route to the next retry tier (or the DLQ, once attempts are
+			// exhausted) and commit the original offset so this poison
+			// message can't wedge the rest of the partition behind it.
+			envelope := envelopeForFailure(msg, &crawlMsg, err)
+			if routeErr := kq.routeFailure(ctx, envelope); routeErr != nil {
+				kq.logger.Error("failed to route failed message to retry/DLQ topic",
+					zap.Error(routeErr),
 					zap.String("url", crawlMsg.URL),
 				)
-				// Don't commit on handler error - will be reprocessed
+				// Leave uncommitted: the next poll redelivers it and tries again.
 				continue
 			}
 
-			// Commit the message
-			if err := kq.reader.CommitMessages(ctx, msg); err != nil {
+			if err := reader.CommitMessages(ctx, msg); err != nil {
 				kq.logger.Error("failed to commit message", zap.Error(err))
 			}
+			continue
+		}
 
-			kq.logger.Info("processed crawl job",
-				zap.Int64("job_id", crawlMsg.JobID),
-				zap.String("url", crawlMsg.URL),
+		// Commit the message
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			kq.logger.Error("failed to commit message", zap.Error(err))
+		}
+
+		kq.logger.Info("processed crawl job",
+			zap.Int64("job_id", crawlMsg.JobID),
+			zap.String("url", crawlMsg.URL),
+		)
+	}
+}
+
+// envelopeForFailure builds the RetryEnvelope for a crawlMsg whose handler
+// just failed, carrying forward the attempt count and first-failure time
+// from msg's headers if this isn't its first trip through the pipeline.
+func envelopeForFailure(msg kafka.Message, crawlMsg *CrawlMessage, handlerErr error) RetryEnvelope {
+	attempts := 1
+	firstFailedAt := time.Now()
+
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case headerRetryAttempts:
+			if n, err := strconv.Atoi(string(h.Value)); err == nil {
+				attempts = n + 1
+			}
+		case headerRetryFirstFailed:
+			if t, err := time.Parse(time.RFC3339Nano, string(h.Value)); err == nil {
+				firstFailedAt = t
+			}
+		}
+	}
+
+	return RetryEnvelope{
+		OriginalTopic: msg.Topic,
+		Message:       *crawlMsg,
+		Attempts:      attempts,
+		LastError:     handlerErr.Error(),
+		FirstFailedAt: firstFailedAt,
+	}
+}
+
+// routeFailure publishes envelope to its next retry tier, or to the DLQ
+// topic once it has exhausted MaxRetryAttempts.
+func (kq *KafkaQueue) routeFailure(ctx context.Context, envelope RetryEnvelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry envelope: %w", err)
+	}
+
+	topic := dlqTopic(kq.config.Topic)
+	toDLQ := envelope.Attempts > kq.config.maxRetryAttempts()
+	if !toDLQ {
+		tierIdx := envelope.Attempts - 1
+		if tierIdx > len(retryTiers)-1 {
+			tierIdx = len(retryTiers) - 1
+		}
+		topic = retryTopic(kq.config.Topic, retryTiers[tierIdx].suffix)
+	}
+
+	msg := kafka.Message{
+		Topic: topic,
+		Key:   []byte(registrableHost(envelope.Message.URL)),
+		Value: data,
+		Time:  time.Now(),
+	}
+
+	if err := kq.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", topic, err)
+	}
+
+	if toDLQ {
+		kq.logger.Warn("routed crawl job to DLQ after exhausting retries",
+			zap.String("url", envelope.Message.URL),
+			zap.Int("attempts", envelope.Attempts),
+			zap.String("last_error", envelope.LastError),
+		)
+	} else {
+		kq.logger.Info("routed failed crawl job to retry tier",
+			zap.String("url", envelope.Message.URL),
+			zap.String("topic", topic),
+			zap.Int("attempts", envelope.Attempts),
+		)
+	}
+
+	return nil
+}
+
+// consumeRetryTier drains one retry-tier topic, waiting out the remainder of
+// tier's delay (measured from when the retry message was published) before
+// republishing the original CrawlMessage to its original topic for another
+// attempt. It runs until ctx is done.
+func (kq *KafkaQueue) consumeRetryTier(ctx context.Context, reader *kafka.Reader, tier retryTier) {
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			kq.logger.Error("failed to fetch retry message",
+				zap.String("tier", tier.suffix),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		var envelope RetryEnvelope
+		if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+			kq.logger.Error("failed to unmarshal retry envelope",
+				zap.String("tier", tier.suffix),
+				zap.Error(err),
+			)
+			if err := reader.CommitMessages(ctx, msg); err != nil {
+				kq.logger.Error("failed to commit retry message", zap.Error(err))
+			}
+			continue
+		}
+
+		if wait := time.Until(msg.Time.Add(tier.delay)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+
+		codec := kq.config.codec()
+		data, err := codec.Encode(&envelope.Message)
+		if err != nil {
+			kq.logger.Error("failed to marshal retried crawl message",
+				zap.String("tier", tier.suffix),
+				zap.Error(err),
 			)
+			continue
 		}
+
+		retried := kafka.Message{
+			Topic: envelope.OriginalTopic,
+			Key:   []byte(registrableHost(envelope.Message.URL)),
+			Value: data,
+			Time:  time.Now(),
+			Headers: []kafka.Header{
+				{Key: headerRetryAttempts, Value: []byte(strconv.Itoa(envelope.Attempts))},
+				{Key: headerRetryFirstFailed, Value: []byte(envelope.FirstFailedAt.Format(time.RFC3339Nano))},
+				{Key: headerRetryLastError, Value: []byte(envelope.LastError)},
+				{Key: headerContentType, Value: []byte(codec.ContentType())},
+			},
+		}
+
+		if err := kq.writer.WriteMessages(ctx, retried); err != nil {
+			kq.logger.Error("failed to republish retried crawl job",
+				zap.String("tier", tier.suffix),
+				zap.String("url", envelope.Message.URL),
+				zap.Error(err),
+			)
+			continue // leave uncommitted, retried again on the next poll
+		}
+
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			kq.logger.Error("failed to commit retry message", zap.Error(err))
+		}
+
+		kq.logger.Info("republished retried crawl job",
+			zap.String("tier", tier.suffix),
+			zap.String("url", envelope.Message.URL),
+			zap.Int("attempts", envelope.Attempts),
+		)
+	}
+}
+
+// ConsumeDLQ drains the dead-letter topic, invoking handler with each failed
+// job's RetryEnvelope (original message, attempt count, and failure history)
+// so an operator can inspect or replay it. A handler error leaves the
+// message uncommitted for redelivery.
+func (kq *KafkaQueue) ConsumeDLQ(ctx context.Context, handler func(*RetryEnvelope) error) error {
+	if kq.dlqReader == nil {
+		return fmt.Errorf("no DLQ reader configured")
+	}
+
+	kq.logger.Info("starting to consume DLQ")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		msg, err := kq.dlqReader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			kq.logger.Error("failed to fetch DLQ message", zap.Error(err))
+			continue
+		}
+
+		var envelope RetryEnvelope
+		if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+			kq.logger.Error("failed to unmarshal DLQ envelope", zap.Error(err))
+			if err := kq.dlqReader.CommitMessages(ctx, msg); err != nil {
+				kq.logger.Error("failed to commit DLQ message", zap.Error(err))
+			}
+			continue
+		}
+
+		if err := handler(&envelope); err != nil {
+			kq.logger.Error("DLQ handler failed",
+				zap.String("url", envelope.Message.URL),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if err := kq.dlqReader.CommitMessages(ctx, msg); err != nil {
+			kq.logger.Error("failed to commit DLQ message", zap.Error(err))
+		}
+	}
+}
+
+// RetryTierStats is a point-in-time queue-depth estimate for one retry tier.
+type RetryTierStats struct {
+	Topic string        `json:"topic"`
+	Delay time.Duration `json:"delay"`
+	Lag   int64         `json:"lag"`
+}
+
+// RetryStats returns a queue-depth estimate for each retry tier, derived
+// from Kafka consumer lag, so operators can see how much work is waiting to
+// be retried.
+func (kq *KafkaQueue) RetryStats() []RetryTierStats {
+	tiers := make([]RetryTierStats, 0, len(kq.retryReaders))
+	for i, r := range kq.retryReaders {
+		stats := r.Stats()
+		tiers = append(tiers, RetryTierStats{
+			Topic: stats.Topic,
+			Delay: retryTiers[i].delay,
+			Lag:   stats.Lag,
+		})
+	}
+	return tiers
+}
+
+// DLQStats is a point-in-time queue-depth estimate for the dead-letter topic.
+type DLQStats struct {
+	Topic string `json:"topic"`
+	Lag   int64  `json:"lag"`
+}
+
+// DLQDepth returns the dead-letter topic's current consumer lag.
+func (kq *KafkaQueue) DLQDepth() DLQStats {
+	if kq.dlqReader == nil {
+		return DLQStats{}
+	}
+	stats := kq.dlqReader.Stats()
+	return DLQStats{Topic: stats.Topic, Lag: stats.Lag}
+}
+
+// FrontierLane is a point-in-time queue-depth estimate for one priority lane
+type FrontierLane struct {
+	Topic    string `json:"topic"`
+	Priority int    `json:"priority"`
+	Lag      int64  `json:"lag"`
+}
+
+// FrontierStats returns a queue-depth estimate per priority lane, derived
+// from each lane's Kafka consumer lag. Kafka only tracks lag per partition,
+// not per host, so this is lane-level rather than true per-host granularity
+// — per-host depth would require decoding the backlog itself.
+func (kq *KafkaQueue) FrontierStats() []FrontierLane {
+	lanes := make([]FrontierLane, 0, len(kq.readers))
+	for i, r := range kq.readers {
+		stats := r.Stats()
+		lanes = append(lanes, FrontierLane{
+			Topic:    stats.Topic,
+			Priority: len(kq.readers) - 1 - i,
+			Lag:      stats.Lag,
+		})
 	}
+	return lanes
 }
 
 // Stats returns writer statistics
@@ -205,22 +951,64 @@ func (kq *KafkaQueue) Stats() kafka.WriterStats {
 	return kq.writer.Stats()
 }
 
-// Close closes the Kafka connections
+// Close closes the Kafka connections. In async mode it first stops accepting
+// new buffered records and waits up to KafkaConfig.AsyncFlushTimeout for the
+// worker pool to drain the buffer and any in-flight produce calls, then tears
+// down the async writer regardless of whether the drain finished in time.
 func (kq *KafkaQueue) Close() error {
-	var errors []error
+	var errs []error
+
+	if kq.config.Async {
+		kq.closeMu.Lock()
+		kq.closed = true
+		kq.closeMu.Unlock()
+
+		close(kq.buffer)
+
+		drained := make(chan struct{})
+		go func() {
+			kq.asyncWG.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-time.After(kq.config.asyncFlushTimeout()):
+			kq.logger.Warn("async flush timed out, closing with buffered records possibly unsent")
+		}
+
+		kq.lifecycleCancel()
+
+		if err := kq.asyncWriter.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("async writer close error: %w", err))
+		}
+		close(kq.deliveryReports)
+	}
 
 	if err := kq.writer.Close(); err != nil {
-		errors = append(errors, fmt.Errorf("writer close error: %w", err))
+		errs = append(errs, fmt.Errorf("writer close error: %w", err))
+	}
+
+	for _, reader := range kq.readers {
+		if err := reader.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("reader close error: %w", err))
+		}
+	}
+
+	for _, reader := range kq.retryReaders {
+		if err := reader.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("retry reader close error: %w", err))
+		}
 	}
 
-	if kq.reader != nil {
-		if err := kq.reader.Close(); err != nil {
-			errors = append(errors, fmt.Errorf("reader close error: %w", err))
+	if kq.dlqReader != nil {
+		if err := kq.dlqReader.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("dlq reader close error: %w", err))
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("close errors: %v", errors)
+	if len(errs) > 0 {
+		return fmt.Errorf("close errors: %v", errs)
 	}
 
 	kq.logger.Info("closed Kafka connections")
@@ -0,0 +1,148 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// KafkaScheduler adapts KafkaQueue's priority-lane topics to the Scheduler
+// interface, so SchedulerService can swap it for RedisScheduler or
+// MemoryScheduler via QUEUE_BACKEND without changing its own code.
+type KafkaScheduler struct {
+	queue *KafkaQueue
+
+	mu       sync.Mutex
+	schedule []int
+	pos      int
+	pending  map[int64]pendingAck // JobID -> fetched-but-uncommitted Kafka message
+}
+
+// pendingAck tracks the reader and raw Kafka message a Dequeue call fetched,
+// so a later Ack/Nack knows what to commit or republish.
+type pendingAck struct {
+	reader *kafka.Reader
+	msg    kafka.Message
+}
+
+// NewKafkaScheduler creates a Scheduler backed by Kafka's priority-lane
+// topics (see KafkaQueue).
+func NewKafkaScheduler(config KafkaConfig, logger *zap.Logger) (*KafkaScheduler, error) {
+	q, err := NewKafkaQueue(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaScheduler{
+		queue:    q,
+		schedule: buildDrainSchedule(config.priorityWeights()),
+		pending:  make(map[int64]pendingAck),
+	}, nil
+}
+
+// Enqueue publishes msg to its priority-lane topic.
+func (s *KafkaScheduler) Enqueue(ctx context.Context, msg *CrawlMessage) error {
+	return s.queue.PublishCrawlJob(ctx, msg)
+}
+
+// EnqueueBatch publishes msgs to their priority-lane topics.
+func (s *KafkaScheduler) EnqueueBatch(ctx context.Context, msgs []*CrawlMessage) error {
+	return s.queue.PublishCrawlJobBatch(ctx, msgs)
+}
+
+// Dequeue fetches the next message off the priority lanes in weighted
+// round-robin order, the same schedule ConsumeCrawlJobs uses.
+func (s *KafkaScheduler) Dequeue(ctx context.Context) (*CrawlMessage, error) {
+	if len(s.queue.readers) == 0 {
+		return nil, fmt.Errorf("no reader configured")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		s.mu.Lock()
+		lane := s.schedule[s.pos]
+		s.pos = (s.pos + 1) % len(s.schedule)
+		s.mu.Unlock()
+
+		reader := s.queue.readers[lane]
+
+		pollCtx, cancel := context.WithTimeout(ctx, lanePollTimeout)
+		raw, err := reader.FetchMessage(pollCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				// Nothing ready on this lane; try the next one.
+				continue
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		var msg CrawlMessage
+		if err := json.Unmarshal(raw.Value, &msg); err != nil {
+			s.queue.logger.Error("failed to unmarshal message, committing and skipping",
+				zap.Error(err),
+				zap.ByteString("value", raw.Value),
+			)
+			if cerr := reader.CommitMessages(ctx, raw); cerr != nil {
+				s.queue.logger.Error("failed to commit unmarshalable message", zap.Error(cerr))
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		s.pending[msg.JobID] = pendingAck{reader: reader, msg: raw}
+		s.mu.Unlock()
+
+		return &msg, nil
+	}
+}
+
+// Ack commits the Kafka offset for a message returned by Dequeue.
+func (s *KafkaScheduler) Ack(ctx context.Context, msg *CrawlMessage) error {
+	s.mu.Lock()
+	p, ok := s.pending[msg.JobID]
+	delete(s.pending, msg.JobID)
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending message for job %d", msg.JobID)
+	}
+	return p.reader.CommitMessages(ctx, p.msg)
+}
+
+// Nack republishes msg rather than committing its offset, since leaving an
+// offset uncommitted only redelivers it to whichever consumer in the group
+// polls that partition next, not necessarily the one best placed to retry.
+func (s *KafkaScheduler) Nack(ctx context.Context, msg *CrawlMessage) error {
+	s.mu.Lock()
+	delete(s.pending, msg.JobID)
+	s.mu.Unlock()
+
+	return s.queue.PublishCrawlJob(ctx, msg)
+}
+
+// Len sums each priority lane's consumer lag as an approximate queue depth.
+func (s *KafkaScheduler) Len(ctx context.Context) (int64, error) {
+	var total int64
+	for _, lane := range s.queue.FrontierStats() {
+		total += lane.Lag
+	}
+	return total, nil
+}
+
+// Close closes the underlying Kafka writer and readers.
+func (s *KafkaScheduler) Close() error {
+	return s.queue.Close()
+}
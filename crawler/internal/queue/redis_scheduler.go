@@ -0,0 +1,358 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RedisConfig holds RedisScheduler configuration.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+
+	// QueueKey is the sorted set holding jobs ready to be dequeued, scored
+	// by readyScore so Dequeue's ZPOPMIN weighs priority against age.
+	// Defaults to "crawl:queue".
+	QueueKey string
+	// DelayedKey is the sorted set holding jobs not yet ready, scored by
+	// their CrawlMessage.NotBefore unix time. Defaults to "crawl:delayed".
+	DelayedKey string
+	// ProcessingKey is the sorted set holding jobs that have been popped off
+	// QueueKey but not yet Acked, scored by the unix time their visibility
+	// timeout expires. Defaults to "crawl:processing".
+	ProcessingKey string
+	// VisibilityTimeout is how long a dequeued job stays reserved in
+	// ProcessingKey before the reclaim sweep puts it back on QueueKey for
+	// redelivery, mirroring the Kafka backend's commit-on-Ack semantics:
+	// a worker that crashes or panics between Dequeue and Ack doesn't lose
+	// the job. Defaults to 5m.
+	VisibilityTimeout time.Duration
+	// SweepInterval is how often the background sweeper moves elapsed
+	// delayed jobs into QueueKey and reclaims expired in-flight jobs from
+	// ProcessingKey back into QueueKey. Defaults to 5s.
+	SweepInterval time.Duration
+}
+
+func (c RedisConfig) queueKey() string {
+	if c.QueueKey == "" {
+		return "crawl:queue"
+	}
+	return c.QueueKey
+}
+
+func (c RedisConfig) delayedKey() string {
+	if c.DelayedKey == "" {
+		return "crawl:delayed"
+	}
+	return c.DelayedKey
+}
+
+func (c RedisConfig) sweepInterval() time.Duration {
+	if c.SweepInterval == 0 {
+		return 5 * time.Second
+	}
+	return c.SweepInterval
+}
+
+func (c RedisConfig) processingKey() string {
+	if c.ProcessingKey == "" {
+		return "crawl:processing"
+	}
+	return c.ProcessingKey
+}
+
+func (c RedisConfig) visibilityTimeout() time.Duration {
+	if c.VisibilityTimeout == 0 {
+		return 5 * time.Minute
+	}
+	return c.VisibilityTimeout
+}
+
+// RedisScheduler is a Scheduler backed by a Redis sorted set used as a
+// priority queue: ZADD with a priority-weighted score, ZPOPMIN to dequeue.
+// A second sorted set holds jobs delayed until a future NotBefore (e.g. the
+// periodic re-crawler's next-due jobs); a third holds jobs popped but not
+// yet Acked, scored by visibility-timeout deadline. A background goroutine
+// sweeps elapsed delayed jobs into the ready queue and reclaims expired
+// in-flight jobs back onto it, so no separate timer service is needed.
+type RedisScheduler struct {
+	client *redis.Client
+	config RedisConfig
+	logger *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRedisScheduler creates a Scheduler backed by Redis and starts its
+// delayed-job sweeper.
+func NewRedisScheduler(config RedisConfig, logger *zap.Logger) *RedisScheduler {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &RedisScheduler{
+		client: client,
+		config: config,
+		logger: logger,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go s.sweepDelayed(ctx)
+
+	logger.Info("created Redis scheduler",
+		zap.String("addr", config.Addr),
+		zap.String("queue_key", config.queueKey()),
+		zap.String("delayed_key", config.delayedKey()),
+	)
+
+	return s
+}
+
+// readyScore ranks a job for the ready queue, where ZPOPMIN takes the lowest
+// score first: the job's unix time minus 10 per priority point. Each priority
+// step is worth ten seconds of age, so among jobs of equal priority the
+// oldest drains first, but a job more than 10s per step older than a
+// higher-priority one drains ahead of it.
+func readyScore(priority int, t time.Time) float64 {
+	return float64(t.Unix()) - float64(priority)*10
+}
+
+func (s *RedisScheduler) marshal(msg *CrawlMessage) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return data, nil
+}
+
+// Enqueue adds msg to the delayed set if its NotBefore is in the future,
+// otherwise straight onto the ready queue.
+func (s *RedisScheduler) Enqueue(ctx context.Context, msg *CrawlMessage) error {
+	msg.CreatedAt = time.Now()
+	data, err := s.marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if msg.NotBefore.After(msg.CreatedAt) {
+		return s.client.ZAdd(ctx, s.config.delayedKey(), redis.Z{
+			Score:  float64(msg.NotBefore.Unix()),
+			Member: data,
+		}).Err()
+	}
+
+	return s.client.ZAdd(ctx, s.config.queueKey(), redis.Z{
+		Score:  readyScore(msg.Priority, msg.CreatedAt),
+		Member: data,
+	}).Err()
+}
+
+// EnqueueBatch adds msgs to the ready or delayed set in a single pipeline.
+func (s *RedisScheduler) EnqueueBatch(ctx context.Context, msgs []*CrawlMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	pipe := s.client.Pipeline()
+	for _, msg := range msgs {
+		msg.CreatedAt = time.Now()
+		data, err := s.marshal(msg)
+		if err != nil {
+			return err
+		}
+
+		if msg.NotBefore.After(msg.CreatedAt) {
+			pipe.ZAdd(ctx, s.config.delayedKey(), redis.Z{Score: float64(msg.NotBefore.Unix()), Member: data})
+			continue
+		}
+		pipe.ZAdd(ctx, s.config.queueKey(), redis.Z{Score: readyScore(msg.Priority, msg.CreatedAt), Member: data})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to enqueue batch: %w", err)
+	}
+	return nil
+}
+
+// dequeuePollInterval bounds how often Dequeue retries ZPOPMIN against an
+// empty queue.
+const dequeuePollInterval = 200 * time.Millisecond
+
+// Dequeue pops the lowest-scored job (see readyScore) off the ready queue, polling until one is available or ctx is done. The job
+// is reserved in the processing set until Ack or Nack, or until the reclaim
+// sweep puts it back on the ready queue after VisibilityTimeout elapses.
+func (s *RedisScheduler) Dequeue(ctx context.Context) (*CrawlMessage, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		result, err := s.client.ZPopMin(ctx, s.config.queueKey(), 1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pop job: %w", err)
+		}
+
+		if len(result) == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(dequeuePollInterval):
+			}
+			continue
+		}
+
+		member, ok := result[0].Member.(string)
+		if !ok {
+			continue
+		}
+
+		var msg CrawlMessage
+		if err := json.Unmarshal([]byte(member), &msg); err != nil {
+			s.logger.Error("failed to unmarshal job, dropping", zap.Error(err))
+			continue
+		}
+
+		deadline := float64(time.Now().Add(s.config.visibilityTimeout()).Unix())
+		if err := s.client.ZAdd(ctx, s.config.processingKey(), redis.Z{Score: deadline, Member: member}).Err(); err != nil {
+			s.logger.Error("failed to reserve job in processing set", zap.Error(err))
+		}
+		return &msg, nil
+	}
+}
+
+// Ack removes msg from the processing set now that it's been handled
+// successfully, so the reclaim sweep won't redeliver it.
+func (s *RedisScheduler) Ack(ctx context.Context, msg *CrawlMessage) error {
+	data, err := s.marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.client.ZRem(ctx, s.config.processingKey(), data).Err()
+}
+
+// Nack removes msg from the processing set and re-enqueues it for
+// redelivery.
+func (s *RedisScheduler) Nack(ctx context.Context, msg *CrawlMessage) error {
+	data, err := s.marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := s.client.ZRem(ctx, s.config.processingKey(), data).Err(); err != nil {
+		s.logger.Error("failed to remove nacked job from processing set", zap.Error(err))
+	}
+	return s.Enqueue(ctx, msg)
+}
+
+// Len reports the ready queue's size. Delayed (not-yet-due) jobs aren't
+// counted, mirroring how a Kafka lag-based estimate only reflects dispatchable work.
+func (s *RedisScheduler) Len(ctx context.Context) (int64, error) {
+	n, err := s.client.ZCard(ctx, s.config.queueKey()).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get queue length: %w", err)
+	}
+	return n, nil
+}
+
+// sweepDelayed periodically promotes delayed jobs whose NotBefore has
+// elapsed into the ready queue.
+func (s *RedisScheduler) sweepDelayed(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.sweepInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.sweepOnce(ctx); err != nil {
+				s.logger.Error("failed to sweep delayed jobs", zap.Error(err))
+			}
+			if err := s.reclaimExpired(ctx); err != nil {
+				s.logger.Error("failed to reclaim expired in-flight jobs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reclaimExpired moves processing-set jobs whose visibility timeout has
+// elapsed back onto the ready queue, so a worker that crashed or panicked
+// after Dequeue without Acking doesn't lose the job permanently.
+func (s *RedisScheduler) reclaimExpired(ctx context.Context) error {
+	now := float64(time.Now().Unix())
+	expired, err := s.client.ZRangeByScore(ctx, s.config.processingKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%f", now),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to scan processing set: %w", err)
+	}
+
+	for _, member := range expired {
+		var msg CrawlMessage
+		if err := json.Unmarshal([]byte(member), &msg); err != nil {
+			s.logger.Error("failed to unmarshal in-flight job, dropping", zap.Error(err))
+			s.client.ZRem(ctx, s.config.processingKey(), member)
+			continue
+		}
+
+		pipe := s.client.TxPipeline()
+		pipe.ZAdd(ctx, s.config.queueKey(), redis.Z{Score: readyScore(msg.Priority, time.Now()), Member: member})
+		pipe.ZRem(ctx, s.config.processingKey(), member)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to reclaim in-flight job: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisScheduler) sweepOnce(ctx context.Context) error {
+	now := float64(time.Now().Unix())
+	due, err := s.client.ZRangeByScore(ctx, s.config.delayedKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%f", now),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to scan delayed jobs: %w", err)
+	}
+
+	for _, member := range due {
+		var msg CrawlMessage
+		if err := json.Unmarshal([]byte(member), &msg); err != nil {
+			s.logger.Error("failed to unmarshal delayed job, dropping", zap.Error(err))
+			s.client.ZRem(ctx, s.config.delayedKey(), member)
+			continue
+		}
+
+		pipe := s.client.TxPipeline()
+		pipe.ZAdd(ctx, s.config.queueKey(), redis.Z{Score: readyScore(msg.Priority, time.Now()), Member: member})
+		pipe.ZRem(ctx, s.config.delayedKey(), member)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to promote delayed job: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close stops the sweeper and closes the Redis client.
+func (s *RedisScheduler) Close() error {
+	s.cancel()
+	<-s.done
+	return s.client.Close()
+}
@@ -0,0 +1,33 @@
+package queue
+
+import "context"
+
+// Scheduler is the transport-agnostic crawl-job queue API that
+// SchedulerService and crawl workers program against. KafkaScheduler,
+// RedisScheduler, and MemoryScheduler are the concrete backends; which one
+// backs a given deployment is chosen by the QUEUE_BACKEND env var
+// ("kafka", "redis", or "memory") in cmd/scheduler.
+type Scheduler interface {
+	// Enqueue publishes a single crawl job.
+	Enqueue(ctx context.Context, msg *CrawlMessage) error
+
+	// EnqueueBatch publishes multiple crawl jobs together.
+	EnqueueBatch(ctx context.Context, msgs []*CrawlMessage) error
+
+	// Dequeue blocks until a crawl job is ready to be worked. The returned
+	// message must be passed to Ack once handled, or Nack to return it to
+	// the queue for redelivery.
+	Dequeue(ctx context.Context) (*CrawlMessage, error)
+
+	// Ack marks a job returned by Dequeue as successfully processed.
+	Ack(ctx context.Context, msg *CrawlMessage) error
+
+	// Nack returns a job returned by Dequeue to the queue for redelivery.
+	Nack(ctx context.Context, msg *CrawlMessage) error
+
+	// Len reports an approximate count of jobs waiting to be dequeued.
+	Len(ctx context.Context) (int64, error)
+
+	// Close releases the backend's connections and background goroutines.
+	Close() error
+}
@@ -0,0 +1,69 @@
+// Package recrawl implements an adaptive re-crawl cadence: an EWMA of how
+// often a URL's content has actually changed between crawls, used to space
+// out crawls of stable pages and tighten the loop on pages that keep
+// changing.
+package recrawl
+
+import "time"
+
+// alpha is the EWMA smoothing factor applied to each crawl's
+// changed/unchanged outcome when updating ChangeScore.
+const alpha = 0.3
+
+// MinInterval and MaxInterval bound how often a URL is ever re-crawled, no
+// matter how volatile or stable it looks.
+const (
+	MinInterval = time.Hour
+	MaxInterval = 30 * 24 * time.Hour
+)
+
+// Policy is the persisted re-crawl cadence for one URL.
+type Policy struct {
+	NextCrawlAt     time.Time
+	IntervalSeconds int64
+	ChangeScore     float64 // EWMA of the changed-fraction across crawls, in [0,1]
+}
+
+// Next computes the Policy to apply after a crawl, given the URL's previous
+// interval and ChangeScore (both zero for a URL's first crawl) and whether
+// this crawl's ContentHash differed from the one before it.
+//
+// ChangeScore is an EWMA of the changed/unchanged outcome (alpha=0.3 weight
+// on the latest observation). A change resets the interval toward
+// MinInterval, since a page that just changed is the one most likely to
+// change again soon; otherwise a high ChangeScore halves the interval and a
+// low one doubles it, both clamped to [MinInterval, MaxInterval].
+func Next(prevIntervalSeconds int64, prevChangeScore float64, changed bool, now time.Time) Policy {
+	observed := 0.0
+	if changed {
+		observed = 1.0
+	}
+	score := alpha*observed + (1-alpha)*prevChangeScore
+
+	interval := time.Duration(prevIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = MinInterval
+	}
+
+	switch {
+	case changed:
+		interval = MinInterval
+	case score >= 0.5:
+		interval /= 2
+	default:
+		interval *= 2
+	}
+
+	if interval < MinInterval {
+		interval = MinInterval
+	}
+	if interval > MaxInterval {
+		interval = MaxInterval
+	}
+
+	return Policy{
+		NextCrawlAt:     now.Add(interval),
+		IntervalSeconds: int64(interval.Seconds()),
+		ChangeScore:     score,
+	}
+}
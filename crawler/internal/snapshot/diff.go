@@ -0,0 +1,227 @@
+package snapshot
+
+import (
+	"strings"
+
+	"github.com/seo-platform/crawler/internal/parser"
+)
+
+// PageDiff describes what changed between two snapshots of the same URL
+type PageDiff struct {
+	AddedHeadings   []string
+	RemovedHeadings []string
+
+	ChangedMeta map[string][2]string // key -> [old, new]
+
+	CanonicalChanged bool
+	OldCanonical     string
+	NewCanonical     string
+
+	AddedLinks   []string
+	RemovedLinks []string
+
+	ArticleDiff []DiffLine
+}
+
+// DiffLine is one line of a textual diff between article bodies
+type DiffLine struct {
+	Op   string // "add", "remove", "equal"
+	Text string
+}
+
+// Diff parses prev and curr with parser.HTMLParser and reports what changed.
+// prev may be nil when there is no earlier snapshot to compare against.
+func Diff(parserInstance *parser.HTMLParser, prev, curr *Snapshot) (*PageDiff, error) {
+	currParsed, err := parserInstance.Parse(curr.HTML, curr.URL)
+	if err != nil {
+		return nil, err
+	}
+	currArticle, err := parserInstance.ExtractArticle(curr.HTML)
+	if err != nil {
+		return nil, err
+	}
+
+	if prev == nil {
+		return &PageDiff{
+			AddedHeadings: allHeadings(currParsed),
+			ChangedMeta:   map[string][2]string{},
+			NewCanonical:  currParsed.CanonicalURL,
+			AddedLinks:    linkHrefs(currParsed),
+			ArticleDiff:   lineDiff("", currArticle.ContentText),
+		}, nil
+	}
+
+	prevParsed, err := parserInstance.Parse(prev.HTML, prev.URL)
+	if err != nil {
+		return nil, err
+	}
+	prevArticle, err := parserInstance.ExtractArticle(prev.HTML)
+	if err != nil {
+		return nil, err
+	}
+
+	added, removed := diffStringSets(allHeadings(prevParsed), allHeadings(currParsed))
+
+	changedMeta := map[string][2]string{}
+	for key, newVal := range currParsed.MetaTags {
+		if oldVal, ok := prevParsed.MetaTags[key]; !ok || oldVal != newVal {
+			changedMeta[key] = [2]string{prevParsed.MetaTags[key], newVal}
+		}
+	}
+	for key, oldVal := range prevParsed.MetaTags {
+		if _, ok := currParsed.MetaTags[key]; !ok {
+			changedMeta[key] = [2]string{oldVal, ""}
+		}
+	}
+
+	addedLinks, removedLinks := diffStringSets(linkHrefs(prevParsed), linkHrefs(currParsed))
+
+	return &PageDiff{
+		AddedHeadings:    added,
+		RemovedHeadings:  removed,
+		ChangedMeta:      changedMeta,
+		CanonicalChanged: prevParsed.CanonicalURL != currParsed.CanonicalURL,
+		OldCanonical:     prevParsed.CanonicalURL,
+		NewCanonical:     currParsed.CanonicalURL,
+		AddedLinks:       addedLinks,
+		RemovedLinks:     removedLinks,
+		ArticleDiff:      lineDiff(prevArticle.ContentText, currArticle.ContentText),
+	}, nil
+}
+
+// allHeadings flattens every heading level into a single slice
+func allHeadings(p *parser.ParsedHTML) []string {
+	var all []string
+	all = append(all, p.Headings.H1...)
+	all = append(all, p.Headings.H2...)
+	all = append(all, p.Headings.H3...)
+	all = append(all, p.Headings.H4...)
+	all = append(all, p.Headings.H5...)
+	all = append(all, p.Headings.H6...)
+	return all
+}
+
+// linkHrefs extracts hrefs from a parsed page's links
+func linkHrefs(p *parser.ParsedHTML) []string {
+	hrefs := make([]string, 0, len(p.Links))
+	for _, l := range p.Links {
+		hrefs = append(hrefs, l.Href)
+	}
+	return hrefs
+}
+
+// lineDiff produces a minimal add/remove/equal diff between two texts,
+// splitting on sentence boundaries so the output is readable for prose
+func lineDiff(oldText, newText string) []DiffLine {
+	oldLines := splitSentences(oldText)
+	newLines := splitSentences(newText)
+
+	lcs := longestCommonSubsequence(oldLines, newLines)
+
+	var diff []DiffLine
+	i, j, k := 0, 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		if k < len(lcs) && i < len(oldLines) && j < len(newLines) && oldLines[i] == lcs[k] && newLines[j] == lcs[k] {
+			diff = append(diff, DiffLine{Op: "equal", Text: lcs[k]})
+			i++
+			j++
+			k++
+			continue
+		}
+		if i < len(oldLines) && (k >= len(lcs) || oldLines[i] != lcs[k]) {
+			diff = append(diff, DiffLine{Op: "remove", Text: oldLines[i]})
+			i++
+			continue
+		}
+		if j < len(newLines) {
+			diff = append(diff, DiffLine{Op: "add", Text: newLines[j]})
+			j++
+		}
+	}
+
+	return diff
+}
+
+// splitSentences splits text on sentence-ending punctuation for a readable
+// diff granularity
+func splitSentences(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	raw := strings.FieldsFunc(text, func(r rune) bool {
+		return r == '.' || r == '\n'
+	})
+
+	sentences := make([]string, 0, len(raw))
+	for _, s := range raw {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+
+	return sentences
+}
+
+// longestCommonSubsequence returns the LCS of two string slices
+func longestCommonSubsequence(a, b []string) []string {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else if table[i-1][j] >= table[i][j-1] {
+				table[i][j] = table[i-1][j]
+			} else {
+				table[i][j] = table[i][j-1]
+			}
+		}
+	}
+
+	var lcs []string
+	for i, j := n, m; i > 0 && j > 0; {
+		switch {
+		case a[i-1] == b[j-1]:
+			lcs = append([]string{a[i-1]}, lcs...)
+			i--
+			j--
+		case table[i-1][j] >= table[i][j-1]:
+			i--
+		default:
+			j--
+		}
+	}
+
+	return lcs
+}
+
+// diffStringSets returns the elements only in b (added) and only in a (removed)
+func diffStringSets(a, b []string) (added, removed []string) {
+	setA := make(map[string]bool, len(a))
+	for _, s := range a {
+		setA[s] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, s := range b {
+		setB[s] = true
+	}
+
+	for _, s := range b {
+		if !setA[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !setB[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
@@ -0,0 +1,254 @@
+// Package snapshot archives crawled pages into self-contained WARC records so
+// SEO consumers can answer "what changed on this page since last crawl?"
+// without re-fetching it.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/seo-platform/crawler/internal/crawler"
+	"go.uber.org/zap"
+)
+
+// trackingAndNoiseSelectors are stripped before hashing/diffing so that ad
+// slots, analytics beacons, and similar churn don't register as a "change"
+var trackingAndNoiseSelectors = "script, noscript, style, iframe[src*='doubleclick'], " +
+	"[class*='ad-'], [id*='ad-'], [class*='tracking'], [class*='analytics']"
+
+// Asset represents an inlined CSS or image resource captured alongside the page
+type Asset struct {
+	URL         string
+	ContentType string
+	Content     []byte
+}
+
+// Snapshot is a self-contained archive of a single crawl of a URL
+type Snapshot struct {
+	URL            string
+	HTML           []byte
+	NormalizedHTML string
+	ContentHash    string // SHA-256 of the normalized DOM
+	SimHash        uint64
+	Assets         []Asset
+}
+
+// Snapshotter captures snapshots of crawled pages, optionally fetching
+// inlined CSS/image assets through the crawler's existing HTTP stack so
+// rate limiting and robots.txt are respected identically to the main crawl
+type Snapshotter struct {
+	httpClient  *crawler.HTTPClient
+	robotsCache *crawler.RobotsCache
+	rateLimiter *crawler.DomainRateLimiter
+	userAgent   string
+	logger      *zap.Logger
+}
+
+// NewSnapshotter creates a new Snapshotter
+func NewSnapshotter(httpClient *crawler.HTTPClient, robotsCache *crawler.RobotsCache, rateLimiter *crawler.DomainRateLimiter, userAgent string, logger *zap.Logger) *Snapshotter {
+	return &Snapshotter{
+		httpClient:  httpClient,
+		robotsCache: robotsCache,
+		rateLimiter: rateLimiter,
+		userAgent:   userAgent,
+		logger:      logger,
+	}
+}
+
+// Capture builds a Snapshot from already-fetched HTML, normalizing it for
+// stable hashing and optionally fetching referenced CSS/image assets
+func (s *Snapshotter) Capture(ctx context.Context, pageURL string, html []byte, includeAssets bool) (*Snapshot, error) {
+	normalized, err := normalizeHTML(html)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize HTML: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(normalized))
+
+	snap := &Snapshot{
+		URL:            pageURL,
+		HTML:           html,
+		NormalizedHTML: normalized,
+		ContentHash:    hex.EncodeToString(hash[:]),
+		SimHash:        simHash(normalized),
+	}
+
+	if includeAssets {
+		snap.Assets = s.fetchAssets(ctx, pageURL, html)
+	}
+
+	return snap, nil
+}
+
+// HasChanged reports whether two content hashes differ, the cheap check
+// consumers of ParsedHTML should run before doing any further processing
+func HasChanged(prevHash, currHash string) bool {
+	return prevHash == "" || prevHash != currHash
+}
+
+// normalizeHTML strips scripts/ads/tracking, sorts attributes, and collapses
+// whitespace so that cosmetic or non-deterministic markup doesn't change the hash
+func normalizeHTML(html []byte) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return "", err
+	}
+
+	doc.Find(trackingAndNoiseSelectors).Remove()
+
+	doc.Find("*").Each(func(i int, sel *goquery.Selection) {
+		if sel.Length() == 0 {
+			return
+		}
+		sortAttributes(sel)
+	})
+
+	rendered, err := doc.Html()
+	if err != nil {
+		return "", err
+	}
+
+	return collapseWhitespace(rendered), nil
+}
+
+// sortAttributes rewrites a node's attributes in lexical order so two
+// semantically identical elements always serialize the same way
+func sortAttributes(sel *goquery.Selection) {
+	attrs := sel.Nodes[0].Attr
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+}
+
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+
+// collapseWhitespace reduces runs of whitespace to a single space
+func collapseWhitespace(s string) string {
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(s, " "))
+}
+
+// simHash computes a simple 64-bit SimHash over whitespace-delimited shingles,
+// giving a locality-sensitive fingerprint that's stable under minor edits
+func simHash(text string) uint64 {
+	tokens := strings.Fields(text)
+
+	var vector [64]int
+	for _, tok := range tokens {
+		h := fnv64a(tok)
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<uint(bit)) != 0 {
+				vector[bit]++
+			} else {
+				vector[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if vector[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+
+	return hash
+}
+
+// fnv64a hashes a string using the FNV-1a algorithm
+func fnv64a(s string) uint64 {
+	const offset64 = 14695981039346656037
+	const prime64 = 1099511628211
+
+	hash := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= prime64
+	}
+	return hash
+}
+
+// fetchAssets pulls CSS and image resources referenced by the page so the
+// archive renders correctly offline. It reuses the crawler's robots.txt
+// check and domain rate limiter exactly like the primary fetch.
+func (s *Snapshotter) fetchAssets(ctx context.Context, pageURL string, html []byte) []Asset {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		s.logger.Warn("failed to parse HTML for asset discovery", zap.String("url", pageURL), zap.Error(err))
+		return nil
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		s.logger.Warn("failed to parse page URL for asset resolution", zap.String("url", pageURL), zap.Error(err))
+		return nil
+	}
+
+	var urls []string
+	doc.Find("link[rel='stylesheet']").Each(func(i int, sel *goquery.Selection) {
+		if href, ok := sel.Attr("href"); ok {
+			if resolved, ok := resolveAssetURL(base, href); ok {
+				urls = append(urls, resolved)
+			}
+		}
+	})
+	doc.Find("img[src]").Each(func(i int, sel *goquery.Selection) {
+		if src, ok := sel.Attr("src"); ok {
+			if resolved, ok := resolveAssetURL(base, src); ok {
+				urls = append(urls, resolved)
+			}
+		}
+	})
+
+	var assets []Asset
+	for _, assetURL := range urls {
+		allowed, err := s.robotsCache.IsAllowed(ctx, assetURL, s.userAgent)
+		if err != nil || !allowed {
+			continue
+		}
+
+		if err := s.rateLimiter.Wait(ctx, hostOf(assetURL)); err != nil {
+			continue
+		}
+
+		resp, err := s.httpClient.Fetch(ctx, assetURL)
+		if err != nil || resp.StatusCode != 200 {
+			s.logger.Warn("failed to fetch inlined asset", zap.String("url", assetURL), zap.Error(err))
+			continue
+		}
+
+		assets = append(assets, Asset{
+			URL:         assetURL,
+			ContentType: resp.ContentType,
+			Content:     resp.Body,
+		})
+	}
+
+	return assets
+}
+
+// resolveAssetURL resolves a discovered href/src against the page's URL so
+// root-relative and relative asset references (the common case) turn into
+// absolute URLs the robots cache, rate limiter, and HTTP client can use
+func resolveAssetURL(base *url.URL, ref string) (string, bool) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	return base.ResolveReference(parsed).String(), true
+}
+
+// hostOf extracts the host to key the rate limiter by, returning the raw
+// string unchanged if it doesn't parse as a URL
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Host
+}
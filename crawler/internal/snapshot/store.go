@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Store persists a Snapshot somewhere a later crawl (or an operator) can read it back
+type Store interface {
+	Save(snap *Snapshot) (string, error)
+}
+
+// FSStore writes snapshots to <baseDir>/<host>/<date>/<urlhash>.warc.gz as
+// gzip-compressed WARC 1.1, so archives can be opened by standard tools
+// (e.g. warcio) without any platform-specific knowledge.
+type FSStore struct {
+	baseDir string
+	logger  *zap.Logger
+}
+
+// NewFSStore creates a new filesystem-backed snapshot store rooted at baseDir
+func NewFSStore(baseDir string, logger *zap.Logger) *FSStore {
+	return &FSStore{baseDir: baseDir, logger: logger}
+}
+
+// Save writes snap to disk and returns the path it was written to
+func (fs *FSStore) Save(snap *Snapshot) (string, error) {
+	parsed, err := url.Parse(snap.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid snapshot URL: %w", err)
+	}
+
+	urlHash := sha256.Sum256([]byte(snap.URL))
+	date := time.Now().UTC().Format("2006-01-02")
+	dir := filepath.Join(fs.baseDir, parsed.Host, date)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	path := filepath.Join(dir, hex.EncodeToString(urlHash[:8])+".warc.gz")
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	defer gz.Close()
+
+	if err := writeWARC(gz, snap); err != nil {
+		return "", fmt.Errorf("failed to write WARC archive: %w", err)
+	}
+
+	fs.logger.Info("saved page snapshot",
+		zap.String("url", snap.URL),
+		zap.String("path", path),
+		zap.String("content_hash", snap.ContentHash),
+		zap.Int("assets", len(snap.Assets)),
+	)
+
+	return path, nil
+}
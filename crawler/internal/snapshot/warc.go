@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// writeWARC writes snap as a minimal WARC 1.1 file: a warcinfo record, a
+// response record for the page, and a resource record per inlined asset
+func writeWARC(w io.Writer, snap *Snapshot) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if err := writeWARCRecord(w, "warcinfo", snap.URL, now, []byte(
+		"software: seo-platform-crawler\r\nformat: WARC File Format 1.1\r\n",
+	)); err != nil {
+		return err
+	}
+
+	if err := writeWARCRecord(w, "response", snap.URL, now, snap.HTML); err != nil {
+		return err
+	}
+
+	for _, asset := range snap.Assets {
+		if err := writeWARCRecord(w, "resource", asset.URL, now, asset.Content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeWARCRecord writes a single WARC 1.1 record with the mandatory headers
+func writeWARCRecord(w io.Writer, recordType, targetURI, date string, block []byte) error {
+	recordID := "<urn:uuid:" + uuid.NewString() + ">"
+
+	header := fmt.Sprintf(
+		"WARC/1.1\r\n"+
+			"WARC-Type: %s\r\n"+
+			"WARC-Record-ID: %s\r\n"+
+			"WARC-Date: %s\r\n"+
+			"WARC-Target-URI: %s\r\n"+
+			"Content-Type: application/http; msgtype=response\r\n"+
+			"Content-Length: %d\r\n"+
+			"\r\n",
+		recordType, recordID, date, targetURI, len(block),
+	)
+
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(block); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\r\n\r\n"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+// Package stats aggregates crawl request outcomes — success/4xx/5xx/robots-
+// denied/timeout counts, bytes fetched, and fetch-duration percentiles —
+// segmented by domain and by crawl job, so operators can see crawl health
+// without grepping Zap logs.
+package stats
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Outcome classifies how a single fetch resolved.
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeClientError
+	OutcomeServerError
+	OutcomeRobotsDenied
+	OutcomeTimeout
+	OutcomeError
+)
+
+// ClassifyOutcome maps a fetch's HTTP status code and error onto an
+// Outcome, for callers translating a crawler.CrawlResult into a Record
+// call.
+func ClassifyOutcome(statusCode int, err error) Outcome {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case err != nil:
+		return OutcomeError
+	case statusCode >= 500:
+		return OutcomeServerError
+	case statusCode >= 400:
+		return OutcomeClientError
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// durationHistogramMax is the ceiling (in milliseconds) the duration
+// histograms track; fetches slower than this are clamped to it rather than
+// dropped.
+const durationHistogramMax = int64(5 * time.Minute / time.Millisecond)
+
+// counters tracks one segment's (one domain's or one job's) running totals.
+type counters struct {
+	mu           sync.Mutex
+	success      int64
+	clientErrors int64
+	serverErrors int64
+	robotsDenied int64
+	timeouts     int64
+	errors       int64
+	bytesFetched int64
+	durations    *hdrhistogram.Histogram
+}
+
+func newCounters() *counters {
+	return &counters{
+		// 1ms floor, 5 minute ceiling, 3 significant digits of precision —
+		// enough resolution for p50/p95/p99 without an unbounded footprint.
+		durations: hdrhistogram.New(1, durationHistogramMax, 3),
+	}
+}
+
+func (c *counters) record(outcome Outcome, bytes int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch outcome {
+	case OutcomeSuccess:
+		c.success++
+	case OutcomeClientError:
+		c.clientErrors++
+	case OutcomeServerError:
+		c.serverErrors++
+	case OutcomeRobotsDenied:
+		c.robotsDenied++
+	case OutcomeTimeout:
+		c.timeouts++
+	case OutcomeError:
+		c.errors++
+	}
+
+	c.bytesFetched += bytes
+	if duration > 0 {
+		millis := duration.Milliseconds()
+		if millis > durationHistogramMax {
+			millis = durationHistogramMax
+		}
+		_ = c.durations.RecordValue(millis)
+	}
+}
+
+// Snapshot is a point-in-time read of one segment's counters.
+type Snapshot struct {
+	Success      int64
+	ClientErrors int64
+	ServerErrors int64
+	RobotsDenied int64
+	Timeouts     int64
+	Errors       int64
+	BytesFetched int64
+	P50Millis    int64
+	P95Millis    int64
+	P99Millis    int64
+}
+
+func (c *counters) snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Snapshot{
+		Success:      c.success,
+		ClientErrors: c.clientErrors,
+		ServerErrors: c.serverErrors,
+		RobotsDenied: c.robotsDenied,
+		Timeouts:     c.timeouts,
+		Errors:       c.errors,
+		BytesFetched: c.bytesFetched,
+		P50Millis:    c.durations.ValueAtQuantile(50),
+		P95Millis:    c.durations.ValueAtQuantile(95),
+		P99Millis:    c.durations.ValueAtQuantile(99),
+	}
+}
+
+// DomainSnapshot is a Snapshot scoped to one domain.
+type DomainSnapshot struct {
+	Domain string
+	Snapshot
+}
+
+// JobSnapshot is a Snapshot scoped to one crawl job, plus the sitemap crawl
+// it's a child of (0 for a standalone URL crawl).
+type JobSnapshot struct {
+	JobID       int64
+	ParentJobID int64
+	Snapshot
+}
+
+type jobEntry struct {
+	parentJobID int64
+	counters    *counters
+}
+
+// Collector aggregates crawl outcomes by domain and by job. It's safe for
+// concurrent use by every worker goroutine processing crawl jobs.
+type Collector struct {
+	mu      sync.RWMutex
+	domains map[string]*counters
+	jobs    map[int64]*jobEntry
+}
+
+// NewCollector creates an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		domains: make(map[string]*counters),
+		jobs:    make(map[int64]*jobEntry),
+	}
+}
+
+func (c *Collector) domainCounters(domain string) *counters {
+	c.mu.RLock()
+	cnt, ok := c.domains[domain]
+	c.mu.RUnlock()
+	if ok {
+		return cnt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cnt, ok := c.domains[domain]; ok {
+		return cnt
+	}
+	cnt = newCounters()
+	c.domains[domain] = cnt
+	return cnt
+}
+
+func (c *Collector) jobCounters(jobID, parentJobID int64) *counters {
+	c.mu.RLock()
+	entry, ok := c.jobs[jobID]
+	c.mu.RUnlock()
+	if ok {
+		return entry.counters
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.jobs[jobID]; ok {
+		return entry.counters
+	}
+	entry = &jobEntry{parentJobID: parentJobID, counters: newCounters()}
+	c.jobs[jobID] = entry
+	return entry.counters
+}
+
+// Record tallies one fetch outcome against domain's running counters.
+func (c *Collector) Record(domain string, outcome Outcome, bytes int64, duration time.Duration) {
+	c.domainCounters(domain).record(outcome, bytes, duration)
+}
+
+// RecordJob tallies one fetch outcome against jobID's running counters.
+// parentJobID is the sitemap crawl's parent job ID, or 0 for a standalone
+// URL crawl.
+func (c *Collector) RecordJob(jobID, parentJobID int64, outcome Outcome, bytes int64, duration time.Duration) {
+	c.jobCounters(jobID, parentJobID).record(outcome, bytes, duration)
+}
+
+// DomainSnapshots returns a Snapshot per domain seen since the last Reset.
+func (c *Collector) DomainSnapshots() []DomainSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]DomainSnapshot, 0, len(c.domains))
+	for domain, cnt := range c.domains {
+		out = append(out, DomainSnapshot{Domain: domain, Snapshot: cnt.snapshot()})
+	}
+	return out
+}
+
+// JobSnapshots returns a Snapshot per job seen since the last Reset.
+func (c *Collector) JobSnapshots() []JobSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]JobSnapshot, 0, len(c.jobs))
+	for jobID, entry := range c.jobs {
+		out = append(out, JobSnapshot{JobID: jobID, ParentJobID: entry.parentJobID, Snapshot: entry.counters.snapshot()})
+	}
+	return out
+}
+
+// Reset clears every domain's and job's counters, starting a fresh window.
+// Call this after persisting a snapshot so the next one reflects only new
+// activity.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.domains = make(map[string]*counters)
+	c.jobs = make(map[int64]*jobEntry)
+}
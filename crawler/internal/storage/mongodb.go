@@ -1,25 +1,61 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
+	"github.com/seo-platform/crawler/internal/diff"
+	"github.com/seo-platform/crawler/internal/parser"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
 
+// defaultGridFSThresholdBytes is the inline-storage ceiling used when
+// NewMongoStorage is given a non-positive threshold: payloads at or below
+// this size are stored directly on the page_content/page_versions document;
+// larger ones are streamed to GridFS instead, to stay well clear of
+// MongoDB's 16 MB per-document limit.
+const defaultGridFSThresholdBytes = 1 << 20 // 1 MB
+
+// orphanGracePeriod is how long a GridFS file is left alone after upload
+// before compactOrphanedFiles will consider deleting it as unreferenced.
+// uploadIfLarge writes the file before the caller saves the page_content/
+// page_versions document that references it, so without this grace period
+// a compaction pass racing that in-between window would see the file as
+// orphaned and delete it out from under the about-to-be-saved reference.
+const orphanGracePeriod = 1 * time.Hour
+
+// compactionInterval is how often the background compaction goroutine
+// sweeps for GridFS files left behind by a deleted page_versions document.
+const compactionInterval = 1 * time.Hour
+
 // MongoStorage handles MongoDB operations for raw HTML and large content
 type MongoStorage struct {
 	client   *mongo.Client
 	database *mongo.Database
 	logger   *zap.Logger
+
+	htmlBucket       *gridfs.Bucket
+	screenshotBucket *gridfs.Bucket
+	gridfsThreshold  int64
+
+	cancel context.CancelFunc
 }
 
-// NewMongoStorage creates a new MongoDB storage instance
-func NewMongoStorage(uri, database string, logger *zap.Logger) (*MongoStorage, error) {
+// NewMongoStorage creates a new MongoDB storage instance. gridfsThreshold is
+// the size in bytes above which HTML and screenshots are stored in GridFS
+// rather than inline; a non-positive value uses defaultGridFSThresholdBytes.
+func NewMongoStorage(uri, database string, gridfsThreshold int64, logger *zap.Logger) (*MongoStorage, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -41,46 +77,142 @@ func NewMongoStorage(uri, database string, logger *zap.Logger) (*MongoStorage, e
 
 	db := client.Database(database)
 
+	htmlBucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("html_bucket"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open html_bucket: %w", err)
+	}
+
+	screenshotBucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("screenshots_bucket"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open screenshots_bucket: %w", err)
+	}
+
+	if gridfsThreshold <= 0 {
+		gridfsThreshold = defaultGridFSThresholdBytes
+	}
+
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+
 	// Create indexes
 	ms := &MongoStorage{
-		client:   client,
-		database: db,
-		logger:   logger,
+		client:           client,
+		database:         db,
+		logger:           logger,
+		htmlBucket:       htmlBucket,
+		screenshotBucket: screenshotBucket,
+		gridfsThreshold:  gridfsThreshold,
+		cancel:           bgCancel,
 	}
 
 	if err := ms.createIndexes(ctx); err != nil {
 		logger.Warn("failed to create indexes", zap.Error(err))
 	}
 
+	go ms.runCompaction(bgCtx)
+
 	return ms, nil
 }
 
-// PageContent represents the content stored in MongoDB
+// ContentRef points at a payload stored in GridFS in place of storing it
+// inline on the referring document.
+type ContentRef struct {
+	Bucket string             `bson:"bucket"`
+	FileID primitive.ObjectID `bson:"file_id"`
+	Size   int64              `bson:"size"`
+	SHA256 string             `bson:"sha256"`
+}
+
+// PageContent represents the content stored in MongoDB. HTML and Screenshot
+// hold the payload inline when it's at or below gridfsThreshold; otherwise
+// they're left empty and HTMLRef/ScreenshotRef point at the GridFS file
+// instead. Use OpenHTMLStream/OpenScreenshotStream to read either case
+// without having to check which one applies.
 type PageContent struct {
-	URL         string                 `bson:"url"`
-	FinalURL    string                 `bson:"final_url"`
-	ContentHash string                 `bson:"content_hash"`
-	HTML        string                 `bson:"html"`
-	PlainText   string                 `bson:"plain_text,omitempty"`
-	Headers     map[string][]string    `bson:"headers"`
-	Metadata    map[string]interface{} `bson:"metadata,omitempty"`
-	Screenshot  []byte                 `bson:"screenshot,omitempty"`
-	CrawledAt   time.Time              `bson:"crawled_at"`
-	CreatedAt   time.Time              `bson:"created_at"`
-	UpdatedAt   time.Time              `bson:"updated_at"`
+	URL           string                 `bson:"url"`
+	URLHash       string                 `bson:"url_hash"`
+	FinalURL      string                 `bson:"final_url"`
+	ContentHash   string                 `bson:"content_hash"`
+	HTML          string                 `bson:"html,omitempty"`
+	HTMLRef       *ContentRef            `bson:"html_ref,omitempty"`
+	PlainText     string                 `bson:"plain_text,omitempty"`
+	Headers       map[string][]string    `bson:"headers"`
+	Metadata      map[string]interface{} `bson:"metadata,omitempty"`
+	Screenshot    []byte                 `bson:"screenshot,omitempty"`
+	ScreenshotRef *ContentRef            `bson:"screenshot_ref,omitempty"`
+	CrawledAt     time.Time              `bson:"crawled_at"`
+	CreatedAt     time.Time              `bson:"created_at"`
+	UpdatedAt     time.Time              `bson:"updated_at"`
 }
 
 // PageVersion represents a historical version of a page
 type PageVersion struct {
 	URL         string                 `bson:"url"`
 	ContentHash string                 `bson:"content_hash"`
-	HTML        string                 `bson:"html"`
+	HTML        string                 `bson:"html,omitempty"`
+	HTMLRef     *ContentRef            `bson:"html_ref,omitempty"`
 	Metadata    map[string]interface{} `bson:"metadata,omitempty"`
 	CrawledAt   time.Time              `bson:"crawled_at"`
 	CreatedAt   time.Time              `bson:"created_at"`
 }
 
-// SavePageContent saves raw HTML content to MongoDB
+// uploadIfLarge uploads payload to bucket and returns a ContentRef when
+// payload exceeds ms.gridfsThreshold, leaving inline empty; otherwise it
+// returns inline unchanged and a nil ref, so the caller stores payload
+// directly on the document.
+func (ms *MongoStorage) uploadIfLarge(ctx context.Context, bucket *gridfs.Bucket, bucketName string, payload []byte) (inline []byte, ref *ContentRef, err error) {
+	if int64(len(payload)) <= ms.gridfsThreshold {
+		return payload, nil, nil
+	}
+
+	hash := sha256.Sum256(payload)
+	uploadStream, err := bucket.OpenUploadStream(bucketName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s upload stream: %w", bucketName, err)
+	}
+
+	if _, err := uploadStream.Write(payload); err != nil {
+		uploadStream.Close()
+		return nil, nil, fmt.Errorf("failed to write %s to gridfs: %w", bucketName, err)
+	}
+	if err := uploadStream.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to finalize %s gridfs upload: %w", bucketName, err)
+	}
+
+	fileID, ok := uploadStream.FileID.(primitive.ObjectID)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected gridfs file id type %T", uploadStream.FileID)
+	}
+
+	return nil, &ContentRef{
+		Bucket: bucketName,
+		FileID: fileID,
+		Size:   int64(len(payload)),
+		SHA256: hex.EncodeToString(hash[:]),
+	}, nil
+}
+
+// downloadRef streams ref's file out of the bucket it names.
+func (ms *MongoStorage) downloadRef(ref *ContentRef) (io.ReadCloser, error) {
+	var bucket *gridfs.Bucket
+	switch ref.Bucket {
+	case "html_bucket":
+		bucket = ms.htmlBucket
+	case "screenshots_bucket":
+		bucket = ms.screenshotBucket
+	default:
+		return nil, fmt.Errorf("unknown gridfs bucket %q", ref.Bucket)
+	}
+
+	stream, err := bucket.OpenDownloadStream(ref.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gridfs download stream: %w", err)
+	}
+	return stream, nil
+}
+
+// SavePageContent saves raw HTML content to MongoDB, streaming HTML and the
+// screenshot to GridFS instead of storing them inline once they exceed
+// ms.gridfsThreshold.
 func (ms *MongoStorage) SavePageContent(ctx context.Context, content *PageContent) error {
 	collection := ms.database.Collection("page_content")
 
@@ -88,6 +220,23 @@ func (ms *MongoStorage) SavePageContent(ctx context.Context, content *PageConten
 	if content.CreatedAt.IsZero() {
 		content.CreatedAt = time.Now()
 	}
+	content.URLHash = urlHash(content.URL)
+
+	inlineHTML, htmlRef, err := ms.uploadIfLarge(ctx, ms.htmlBucket, "html_bucket", []byte(content.HTML))
+	if err != nil {
+		return fmt.Errorf("failed to store html: %w", err)
+	}
+	content.HTML = string(inlineHTML)
+	content.HTMLRef = htmlRef
+
+	if len(content.Screenshot) > 0 {
+		inlineShot, shotRef, err := ms.uploadIfLarge(ctx, ms.screenshotBucket, "screenshots_bucket", content.Screenshot)
+		if err != nil {
+			return fmt.Errorf("failed to store screenshot: %w", err)
+		}
+		content.Screenshot = inlineShot
+		content.ScreenshotRef = shotRef
+	}
 
 	filter := bson.M{"url": content.URL}
 	update := bson.M{
@@ -112,7 +261,10 @@ func (ms *MongoStorage) SavePageContent(ctx context.Context, content *PageConten
 	return nil
 }
 
-// GetPageContent retrieves page content by URL
+// GetPageContent retrieves page content by URL. If the HTML or screenshot
+// was stored in GridFS, the returned PageContent carries only the
+// reference (HTMLRef/ScreenshotRef) rather than the payload itself — use
+// OpenHTMLStream/OpenScreenshotStream to read it.
 func (ms *MongoStorage) GetPageContent(ctx context.Context, url string) (*PageContent, error) {
 	collection := ms.database.Collection("page_content")
 
@@ -130,13 +282,78 @@ func (ms *MongoStorage) GetPageContent(ctx context.Context, url string) (*PageCo
 	return &content, nil
 }
 
-// SavePageVersion saves a historical version of a page
+// urlHash is the hex-encoded SHA-256 of url, used as the stable,
+// path-safe identifier content-service's /pages/:urlHash/diff route looks
+// content up by.
+func urlHash(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetPageContentByURLHash resolves the urlHash path parameter used by
+// content-service's diff endpoint back to the page content it names.
+func (ms *MongoStorage) GetPageContentByURLHash(ctx context.Context, urlHash string) (*PageContent, error) {
+	collection := ms.database.Collection("page_content")
+
+	var content PageContent
+	err := collection.FindOne(ctx, bson.M{"url_hash": urlHash}).Decode(&content)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no page content for url hash %q", urlHash)
+		}
+		return nil, fmt.Errorf("failed to get page content by url hash: %w", err)
+	}
+
+	return &content, nil
+}
+
+// OpenHTMLStream returns url's HTML body as a stream, reading it out of
+// GridFS if it was too large to store inline, so callers can process it
+// without loading the full body into memory.
+func (ms *MongoStorage) OpenHTMLStream(ctx context.Context, url string) (io.ReadCloser, error) {
+	content, err := ms.GetPageContent(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if content.HTMLRef != nil {
+		return ms.downloadRef(content.HTMLRef)
+	}
+	return io.NopCloser(bytes.NewReader([]byte(content.HTML))), nil
+}
+
+// OpenScreenshotStream returns url's screenshot as a stream, reading it out
+// of GridFS if it was too large to store inline.
+func (ms *MongoStorage) OpenScreenshotStream(ctx context.Context, url string) (io.ReadCloser, error) {
+	content, err := ms.GetPageContent(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if content.ScreenshotRef != nil {
+		return ms.downloadRef(content.ScreenshotRef)
+	}
+	if len(content.Screenshot) == 0 {
+		return nil, fmt.Errorf("no screenshot for url")
+	}
+	return io.NopCloser(bytes.NewReader(content.Screenshot)), nil
+}
+
+// SavePageVersion saves a historical version of a page, streaming HTML to
+// GridFS instead of storing it inline once it exceeds ms.gridfsThreshold.
 func (ms *MongoStorage) SavePageVersion(ctx context.Context, version *PageVersion) error {
 	collection := ms.database.Collection("page_versions")
 
 	version.CreatedAt = time.Now()
 
-	_, err := collection.InsertOne(ctx, version)
+	inlineHTML, htmlRef, err := ms.uploadIfLarge(ctx, ms.htmlBucket, "html_bucket", []byte(version.HTML))
+	if err != nil {
+		return fmt.Errorf("failed to store html: %w", err)
+	}
+	version.HTML = string(inlineHTML)
+	version.HTMLRef = htmlRef
+
+	_, err = collection.InsertOne(ctx, version)
 	if err != nil {
 		return fmt.Errorf("failed to save page version: %w", err)
 	}
@@ -172,15 +389,275 @@ func (ms *MongoStorage) GetPageVersions(ctx context.Context, url string, limit i
 	return versions, nil
 }
 
-// SaveScreenshot saves a screenshot for a page
+// SEODelta summarizes the high-level SEO-relevant changes between two page
+// versions, computed from the same parse DiffVersions runs to build the
+// raw diff hunks.
+type SEODelta struct {
+	TitleChanged       bool     `bson:"title_changed" json:"title_changed"`
+	TitleBefore        string   `bson:"title_before,omitempty" json:"title_before,omitempty"`
+	TitleAfter         string   `bson:"title_after,omitempty" json:"title_after,omitempty"`
+	DescriptionChanged bool     `bson:"description_changed" json:"description_changed"`
+	DescriptionBefore  string   `bson:"description_before,omitempty" json:"description_before,omitempty"`
+	DescriptionAfter   string   `bson:"description_after,omitempty" json:"description_after,omitempty"`
+	CanonicalChanged   bool     `bson:"canonical_changed" json:"canonical_changed"`
+	CanonicalBefore    string   `bson:"canonical_before,omitempty" json:"canonical_before,omitempty"`
+	CanonicalAfter     string   `bson:"canonical_after,omitempty" json:"canonical_after,omitempty"`
+	H1Added            []string `bson:"h1_added,omitempty" json:"h1_added,omitempty"`
+	H1Removed          []string `bson:"h1_removed,omitempty" json:"h1_removed,omitempty"`
+	LinksAdded         []string `bson:"links_added,omitempty" json:"links_added,omitempty"`
+	LinksRemoved       []string `bson:"links_removed,omitempty" json:"links_removed,omitempty"`
+	SchemaTypesAdded   []string `bson:"schema_types_added,omitempty" json:"schema_types_added,omitempty"`
+	SchemaTypesRemoved []string `bson:"schema_types_removed,omitempty" json:"schema_types_removed,omitempty"`
+}
+
+// PageDiff is the cached result of diffing two stored versions of a URL,
+// identified by their content hashes.
+type PageDiff struct {
+	URL       string      `bson:"url" json:"url"`
+	HashA     string      `bson:"hash_a" json:"hash_a"`
+	HashB     string      `bson:"hash_b" json:"hash_b"`
+	Hunks     []diff.Hunk `bson:"hunks" json:"hunks"`
+	SEODelta  SEODelta    `bson:"seo_delta" json:"seo_delta"`
+	CreatedAt time.Time   `bson:"created_at" json:"created_at"`
+}
+
+// DiffVersions loads the two page_versions documents for url matching
+// hashA and hashB, normalizes both HTML bodies into a tag-boundary text
+// stream (internal/parser.NormalizedTextLines), diffs them with
+// internal/diff, and layers on a higher-level SEO delta (title, meta
+// description, canonical, h1 set, outbound links, JSON-LD schema.org
+// types). The result is cached in page_diffs keyed by (url, hashA, hashB)
+// so a repeated query skips straight to the cached document instead of
+// re-parsing both HTML bodies.
+func (ms *MongoStorage) DiffVersions(ctx context.Context, url, hashA, hashB string) (*PageDiff, error) {
+	diffsCollection := ms.database.Collection("page_diffs")
+
+	var cached PageDiff
+	err := diffsCollection.FindOne(ctx, bson.M{"url": url, "hash_a": hashA, "hash_b": hashB}).Decode(&cached)
+	if err == nil {
+		return &cached, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to check page diff cache: %w", err)
+	}
+
+	versionA, err := ms.getPageVersionByHash(ctx, url, hashA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load version %q: %w", hashA, err)
+	}
+	versionB, err := ms.getPageVersionByHash(ctx, url, hashB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load version %q: %w", hashB, err)
+	}
+
+	htmlA, err := ms.readVersionHTML(versionA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version %q html: %w", hashA, err)
+	}
+	htmlB, err := ms.readVersionHTML(versionB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version %q html: %w", hashB, err)
+	}
+
+	htmlParser := parser.NewHTMLParser(ms.logger)
+
+	linesA, err := htmlParser.NormalizedTextLines(htmlA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize version %q: %w", hashA, err)
+	}
+	linesB, err := htmlParser.NormalizedTextLines(htmlB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize version %q: %w", hashB, err)
+	}
+
+	parsedA, err := htmlParser.Parse(htmlA, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %q: %w", hashA, err)
+	}
+	parsedB, err := htmlParser.Parse(htmlB, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %q: %w", hashB, err)
+	}
+
+	pageDiff := &PageDiff{
+		URL:       url,
+		HashA:     hashA,
+		HashB:     hashB,
+		Hunks:     diff.Lines(linesA, linesB),
+		SEODelta:  seoDelta(parsedA, parsedB),
+		CreatedAt: time.Now(),
+	}
+
+	_, err = diffsCollection.UpdateOne(ctx,
+		bson.M{"url": url, "hash_a": hashA, "hash_b": hashB},
+		bson.M{"$set": pageDiff},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		ms.logger.Warn("failed to cache page diff", zap.String("url", url), zap.Error(err))
+	}
+
+	return pageDiff, nil
+}
+
+// getPageVersionByHash loads the page_versions document for url whose
+// content_hash is hash.
+func (ms *MongoStorage) getPageVersionByHash(ctx context.Context, url, hash string) (*PageVersion, error) {
+	collection := ms.database.Collection("page_versions")
+
+	var version PageVersion
+	err := collection.FindOne(ctx, bson.M{"url": url, "content_hash": hash}).Decode(&version)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no page version for url %q hash %q", url, hash)
+		}
+		return nil, fmt.Errorf("failed to get page version: %w", err)
+	}
+
+	return &version, nil
+}
+
+// readVersionHTML returns v's full HTML body, downloading it from GridFS
+// first if it was too large to store inline.
+func (ms *MongoStorage) readVersionHTML(v *PageVersion) ([]byte, error) {
+	if v.HTMLRef == nil {
+		return []byte(v.HTML), nil
+	}
+
+	stream, err := ms.downloadRef(v.HTMLRef)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return io.ReadAll(stream)
+}
+
+// seoDelta compares two parsed pages and summarizes the SEO-relevant
+// changes between them.
+func seoDelta(a, b *parser.ParsedHTML) SEODelta {
+	delta := SEODelta{
+		TitleChanged:       a.Title != b.Title,
+		DescriptionChanged: a.Description != b.Description,
+		CanonicalChanged:   a.CanonicalURL != b.CanonicalURL,
+	}
+	if delta.TitleChanged {
+		delta.TitleBefore, delta.TitleAfter = a.Title, b.Title
+	}
+	if delta.DescriptionChanged {
+		delta.DescriptionBefore, delta.DescriptionAfter = a.Description, b.Description
+	}
+	if delta.CanonicalChanged {
+		delta.CanonicalBefore, delta.CanonicalAfter = a.CanonicalURL, b.CanonicalURL
+	}
+
+	delta.H1Removed, delta.H1Added = stringSetDiff(a.Headings.H1, b.Headings.H1)
+
+	delta.LinksRemoved, delta.LinksAdded = stringSetDiff(linkHrefs(a.Links), linkHrefs(b.Links))
+
+	delta.SchemaTypesRemoved, delta.SchemaTypesAdded = stringSetDiff(schemaTypes(a.StructuredData), schemaTypes(b.StructuredData))
+
+	return delta
+}
+
+// linkHrefs projects a page's links down to their hrefs, for set-diffing.
+func linkHrefs(links []parser.Link) []string {
+	hrefs := make([]string, len(links))
+	for i, l := range links {
+		hrefs[i] = l.Href
+	}
+	return hrefs
+}
+
+// stringSetDiff returns the elements of before absent from after
+// ("removed") and the elements of after absent from before ("added"),
+// each de-duplicated and in first-seen order.
+func stringSetDiff(before, after []string) (removed, added []string) {
+	beforeSet := make(map[string]bool, len(before))
+	for _, s := range before {
+		beforeSet[s] = true
+	}
+	afterSet := make(map[string]bool, len(after))
+	for _, s := range after {
+		afterSet[s] = true
+	}
+
+	seen := make(map[string]bool, len(before))
+	for _, s := range before {
+		if !afterSet[s] && !seen[s] {
+			removed = append(removed, s)
+			seen[s] = true
+		}
+	}
+
+	seen = make(map[string]bool, len(after))
+	for _, s := range after {
+		if !beforeSet[s] && !seen[s] {
+			added = append(added, s)
+			seen[s] = true
+		}
+	}
+
+	return removed, added
+}
+
+// schemaTypes extracts every schema.org "@type" value from a page's
+// JSON-LD blocks (including ones nested under "@graph"), so DiffVersions
+// can flag a changed structured-data type even when the surrounding
+// JSON-LD payload is otherwise untouched.
+func schemaTypes(blocks []string) []string {
+	var types []string
+	for _, block := range blocks {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(block), &doc); err != nil {
+			continue
+		}
+		collectSchemaTypes(doc, &types)
+	}
+	return types
+}
+
+func collectSchemaTypes(doc interface{}, types *[]string) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		switch t := v["@type"].(type) {
+		case string:
+			*types = append(*types, t)
+		case []interface{}:
+			for _, item := range t {
+				if s, ok := item.(string); ok {
+					*types = append(*types, s)
+				}
+			}
+		}
+		if graph, ok := v["@graph"].([]interface{}); ok {
+			for _, item := range graph {
+				collectSchemaTypes(item, types)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectSchemaTypes(item, types)
+		}
+	}
+}
+
+// SaveScreenshot saves a screenshot for a page, streaming it to GridFS
+// instead of storing it inline once it exceeds ms.gridfsThreshold.
 func (ms *MongoStorage) SaveScreenshot(ctx context.Context, url string, screenshot []byte) error {
 	collection := ms.database.Collection("page_content")
 
+	inlineShot, shotRef, err := ms.uploadIfLarge(ctx, ms.screenshotBucket, "screenshots_bucket", screenshot)
+	if err != nil {
+		return fmt.Errorf("failed to store screenshot: %w", err)
+	}
+
 	filter := bson.M{"url": url}
 	update := bson.M{
 		"$set": bson.M{
-			"screenshot":  screenshot,
-			"updated_at": time.Now(),
+			"screenshot":     inlineShot,
+			"screenshot_ref": shotRef,
+			"updated_at":     time.Now(),
 		},
 	}
 
@@ -201,18 +678,39 @@ func (ms *MongoStorage) SaveScreenshot(ctx context.Context, url string, screensh
 	return nil
 }
 
-// DeleteOldVersions deletes versions older than the specified duration
+// DeleteOldVersions deletes versions older than the specified duration,
+// along with any GridFS file they referenced.
 func (ms *MongoStorage) DeleteOldVersions(ctx context.Context, olderThan time.Duration) (int64, error) {
 	collection := ms.database.Collection("page_versions")
 
 	cutoff := time.Now().Add(-olderThan)
 	filter := bson.M{"created_at": bson.M{"$lt": cutoff}}
 
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"html_ref": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find old versions: %w", err)
+	}
+	var toDelete []struct {
+		HTMLRef *ContentRef `bson:"html_ref"`
+	}
+	if err := cursor.All(ctx, &toDelete); err != nil {
+		return 0, fmt.Errorf("failed to decode old versions: %w", err)
+	}
+
 	result, err := collection.DeleteMany(ctx, filter)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete old versions: %w", err)
 	}
 
+	for _, v := range toDelete {
+		if v.HTMLRef == nil {
+			continue
+		}
+		if err := ms.htmlBucket.Delete(v.HTMLRef.FileID); err != nil {
+			ms.logger.Warn("failed to delete orphaned gridfs file", zap.Error(err))
+		}
+	}
+
 	ms.logger.Info("deleted old versions",
 		zap.Int64("count", result.DeletedCount),
 		zap.Time("cutoff", cutoff),
@@ -221,6 +719,100 @@ func (ms *MongoStorage) DeleteOldVersions(ctx context.Context, olderThan time.Du
 	return result.DeletedCount, nil
 }
 
+// runCompaction periodically sweeps GridFS for files no page_content or
+// page_versions document references any more. This is a safety net for
+// DeleteOldVersions's own best-effort cleanup (e.g. a process crash between
+// the document delete and the GridFS delete); it's not the primary path.
+func (ms *MongoStorage) runCompaction(ctx context.Context) {
+	ticker := time.NewTicker(compactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := ms.compactOrphanedFiles(ctx, ms.htmlBucket, "html_bucket", "html_ref"); err != nil {
+				ms.logger.Error("failed to compact html_bucket", zap.Error(err))
+			} else if n > 0 {
+				ms.logger.Info("compacted orphaned gridfs files", zap.String("bucket", "html_bucket"), zap.Int64("count", n))
+			}
+
+			if n, err := ms.compactOrphanedFiles(ctx, ms.screenshotBucket, "screenshots_bucket", "screenshot_ref"); err != nil {
+				ms.logger.Error("failed to compact screenshots_bucket", zap.Error(err))
+			} else if n > 0 {
+				ms.logger.Info("compacted orphaned gridfs files", zap.String("bucket", "screenshots_bucket"), zap.Int64("count", n))
+			}
+		}
+	}
+}
+
+// compactOrphanedFiles deletes every file in bucket older than
+// orphanGracePeriod whose _id isn't referenced by refField on any
+// page_content or page_versions document.
+func (ms *MongoStorage) compactOrphanedFiles(ctx context.Context, bucket *gridfs.Bucket, bucketName, refField string) (int64, error) {
+	referenced := make(map[primitive.ObjectID]bool)
+	for _, collName := range []string{"page_content", "page_versions"} {
+		cursor, err := ms.database.Collection(collName).Find(ctx,
+			bson.M{refField: bson.M{"$ne": nil}},
+			options.Find().SetProjection(bson.M{refField: 1}),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan %s for gridfs refs: %w", collName, err)
+		}
+
+		for cursor.Next(ctx) {
+			var raw bson.M
+			if err := cursor.Decode(&raw); err != nil {
+				cursor.Close(ctx)
+				return 0, fmt.Errorf("failed to decode gridfs ref: %w", err)
+			}
+			refRaw, ok := raw[refField]
+			if !ok || refRaw == nil {
+				continue
+			}
+			refDoc, ok := refRaw.(bson.M)
+			if !ok {
+				continue
+			}
+			if fileID, ok := refDoc["file_id"].(primitive.ObjectID); ok {
+				referenced[fileID] = true
+			}
+		}
+		cursor.Close(ctx)
+	}
+
+	filesCollection := ms.database.Collection(bucketName + ".files")
+	cursor, err := filesCollection.Find(ctx,
+		bson.M{"uploadDate": bson.M{"$lt": time.Now().Add(-orphanGracePeriod)}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s files: %w", bucketName, err)
+	}
+	defer cursor.Close(ctx)
+
+	var deleted int64
+	for cursor.Next(ctx) {
+		var file struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&file); err != nil {
+			return deleted, fmt.Errorf("failed to decode %s file: %w", bucketName, err)
+		}
+		if referenced[file.ID] {
+			continue
+		}
+		if err := bucket.Delete(file.ID); err != nil {
+			ms.logger.Warn("failed to delete orphaned gridfs file", zap.String("bucket", bucketName), zap.Error(err))
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, cursor.Err()
+}
+
 // createIndexes creates necessary indexes for collections
 func (ms *MongoStorage) createIndexes(ctx context.Context) error {
 	// Indexes for page_content
@@ -230,6 +822,10 @@ func (ms *MongoStorage) createIndexes(ctx context.Context) error {
 			Keys:    bson.D{{Key: "url", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys:    bson.D{{Key: "url_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
 		{
 			Keys: bson.D{{Key: "content_hash", Value: 1}},
 		},
@@ -265,12 +861,29 @@ func (ms *MongoStorage) createIndexes(ctx context.Context) error {
 		return fmt.Errorf("failed to create version indexes: %w", err)
 	}
 
+	// Index for page_diffs, keyed by the same (url, hashA, hashB) DiffVersions
+	// caches against.
+	diffsCollection := ms.database.Collection("page_diffs")
+	_, err = diffsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "url", Value: 1},
+			{Key: "hash_a", Value: 1},
+			{Key: "hash_b", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create diff indexes: %w", err)
+	}
+
 	ms.logger.Info("created MongoDB indexes")
 	return nil
 }
 
 // Close closes the MongoDB connection
 func (ms *MongoStorage) Close(ctx context.Context) error {
+	ms.cancel()
+
 	if err := ms.client.Disconnect(ctx); err != nil {
 		return fmt.Errorf("failed to disconnect from MongoDB: %w", err)
 	}
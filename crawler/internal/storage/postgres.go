@@ -8,12 +8,18 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+
+	"github.com/seo-platform/crawler/internal/auth"
+	"github.com/seo-platform/crawler/internal/crawler"
+	"github.com/seo-platform/crawler/internal/recrawl"
+	"github.com/seo-platform/crawler/internal/stats"
 )
 
 // PostgresStorage handles PostgreSQL operations for crawler metadata
 type PostgresStorage struct {
-	pool   *pgxpool.Pool
-	logger *zap.Logger
+	pool       *pgxpool.Pool
+	logger     *zap.Logger
+	normalizer *crawler.URLNormalizer
 }
 
 // NewPostgresStorage creates a new PostgreSQL storage instance
@@ -42,8 +48,9 @@ func NewPostgresStorage(connString string, logger *zap.Logger) (*PostgresStorage
 	logger.Info("connected to PostgreSQL")
 
 	return &PostgresStorage{
-		pool:   pool,
-		logger: logger,
+		pool:       pool,
+		logger:     logger,
+		normalizer: crawler.NewURLNormalizer(),
 	}, nil
 }
 
@@ -62,6 +69,14 @@ type CrawlJob struct {
 	CompletedAt *time.Time
 	Error       *string
 	Metadata    map[string]interface{}
+
+	// NextCrawlAt, IntervalSeconds, and ChangeScore hold the adaptive
+	// re-crawl cadence computed by internal/recrawl: when this URL is next
+	// due, the current interval between crawls, and the EWMA of how often
+	// its content has actually been changing.
+	NextCrawlAt     *time.Time
+	IntervalSeconds int64
+	ChangeScore     float64
 }
 
 // PageMetadata represents metadata for a crawled page
@@ -77,19 +92,28 @@ type PageMetadata struct {
 	Description  string
 	Keywords     string
 	CanonicalURL string
-	Language     string
-	H1Count      int
-	H2Count      int
-	ImageCount   int
-	LinkCount    int
-	InternalLinks int
-	ExternalLinks int
-	WordCount    int
-	LoadTime     int
-	CrawledAt    time.Time
-	FirstSeenAt  time.Time
-	LastSeenAt   time.Time
-	ChangeCount  int
+	// CanonicalURLKey is URL run through crawler.URLNormalizer, so syntactic
+	// variants of the same page (scheme/host case, default port, dot
+	// segments, query order, tracking params, ...) resolve to one row.
+	CanonicalURLKey string
+	Language        string
+	H1Count         int
+	H2Count         int
+	ImageCount      int
+	LinkCount       int
+	InternalLinks   int
+	ExternalLinks   int
+	WordCount       int
+	LoadTime        int
+	CrawledAt       time.Time
+	FirstSeenAt     time.Time
+	LastSeenAt      time.Time
+	ChangeCount     int
+	// ETag and LastModified are the validators the server returned for this
+	// page, persisted so the next crawl can revalidate with a conditional
+	// GET instead of re-downloading the body.
+	ETag         string
+	LastModified string
 }
 
 // CreateCrawlJob creates a new crawl job
@@ -175,20 +199,31 @@ func (ps *PostgresStorage) GetCrawlJob(ctx context.Context, id int64) (*CrawlJob
 	return &job, nil
 }
 
-// SavePageMetadata saves or updates page metadata
+// SavePageMetadata saves or updates page metadata. It dedupes on
+// canonical_url_key rather than the raw url column, so two syntactic
+// variants of the same page (http vs. HTTP scheme, a default port, a
+// trailing dot segment, re-ordered query params, ...) update the same row
+// instead of creating a second one.
 func (ps *PostgresStorage) SavePageMetadata(ctx context.Context, metadata *PageMetadata) error {
+	canonicalKey, err := ps.normalizer.Normalize(metadata.URL)
+	if err != nil {
+		return fmt.Errorf("failed to normalize URL: %w", err)
+	}
+
 	query := `
 		INSERT INTO page_metadata (
-			url, final_url, domain, status_code, content_type, content_hash,
+			url, canonical_url_key, final_url, domain, status_code, content_type, content_hash,
 			title, description, keywords, canonical_url, language,
 			h1_count, h2_count, image_count, link_count,
 			internal_links, external_links, word_count, load_time,
-			crawled_at, first_seen_at, last_seen_at, change_count
+			crawled_at, first_seen_at, last_seen_at, change_count,
+			etag, last_modified
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
-			$16, $17, $18, $19, $20, $21, $22, $23
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26
 		)
-		ON CONFLICT (url) DO UPDATE SET
+		ON CONFLICT (canonical_url_key) DO UPDATE SET
+			url = EXCLUDED.url,
 			final_url = EXCLUDED.final_url,
 			status_code = EXCLUDED.status_code,
 			content_type = EXCLUDED.content_type,
@@ -208,13 +243,16 @@ func (ps *PostgresStorage) SavePageMetadata(ctx context.Context, metadata *PageM
 			load_time = EXCLUDED.load_time,
 			crawled_at = EXCLUDED.crawled_at,
 			last_seen_at = EXCLUDED.last_seen_at,
-			change_count = page_metadata.change_count + 1
+			change_count = page_metadata.change_count + 1,
+			etag = EXCLUDED.etag,
+			last_modified = EXCLUDED.last_modified
 		RETURNING id
 	`
 
 	var id int64
-	err := ps.pool.QueryRow(ctx, query,
+	err = ps.pool.QueryRow(ctx, query,
 		metadata.URL,
+		canonicalKey,
 		metadata.FinalURL,
 		metadata.Domain,
 		metadata.StatusCode,
@@ -237,12 +275,16 @@ func (ps *PostgresStorage) SavePageMetadata(ctx context.Context, metadata *PageM
 		time.Now(), // first_seen_at
 		time.Now(), // last_seen_at
 		0,          // change_count
+		metadata.ETag,
+		metadata.LastModified,
 	).Scan(&id)
 
 	if err != nil {
 		return fmt.Errorf("failed to save page metadata: %w", err)
 	}
 
+	metadata.CanonicalURLKey = canonicalKey
+
 	metadata.ID = id
 
 	ps.logger.Info("saved page metadata",
@@ -253,22 +295,30 @@ func (ps *PostgresStorage) SavePageMetadata(ctx context.Context, metadata *PageM
 	return nil
 }
 
-// GetPageMetadata retrieves page metadata by URL
+// GetPageMetadata retrieves page metadata by URL, matching on canonical_url_key
+// so any syntactic variant of the stored URL resolves to the same row.
 func (ps *PostgresStorage) GetPageMetadata(ctx context.Context, url string) (*PageMetadata, error) {
+	canonicalKey, err := ps.normalizer.Normalize(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize URL: %w", err)
+	}
+
 	query := `
-		SELECT id, url, final_url, domain, status_code, content_type, content_hash,
+		SELECT id, url, canonical_url_key, final_url, domain, status_code, content_type, content_hash,
 		       title, description, keywords, canonical_url, language,
 		       h1_count, h2_count, image_count, link_count,
 		       internal_links, external_links, word_count, load_time,
-		       crawled_at, first_seen_at, last_seen_at, change_count
+		       crawled_at, first_seen_at, last_seen_at, change_count,
+		       etag, last_modified
 		FROM page_metadata
-		WHERE url = $1
+		WHERE canonical_url_key = $1
 	`
 
 	var metadata PageMetadata
-	err := ps.pool.QueryRow(ctx, query, url).Scan(
+	err = ps.pool.QueryRow(ctx, query, canonicalKey).Scan(
 		&metadata.ID,
 		&metadata.URL,
+		&metadata.CanonicalURLKey,
 		&metadata.FinalURL,
 		&metadata.Domain,
 		&metadata.StatusCode,
@@ -291,6 +341,8 @@ func (ps *PostgresStorage) GetPageMetadata(ctx context.Context, url string) (*Pa
 		&metadata.FirstSeenAt,
 		&metadata.LastSeenAt,
 		&metadata.ChangeCount,
+		&metadata.ETag,
+		&metadata.LastModified,
 	)
 
 	if err != nil {
@@ -303,16 +355,23 @@ func (ps *PostgresStorage) GetPageMetadata(ctx context.Context, url string) (*Pa
 	return &metadata, nil
 }
 
-// CheckContentChange checks if content has changed based on hash
+// CheckContentChange checks if content has changed based on hash, matching
+// on canonical_url_key so a syntactic variant of a previously-seen URL is
+// still recognized as the same page.
 func (ps *PostgresStorage) CheckContentChange(ctx context.Context, url, contentHash string) (bool, error) {
+	canonicalKey, err := ps.normalizer.Normalize(url)
+	if err != nil {
+		return false, fmt.Errorf("failed to normalize URL: %w", err)
+	}
+
 	query := `
 		SELECT content_hash
 		FROM page_metadata
-		WHERE url = $1
+		WHERE canonical_url_key = $1
 	`
 
 	var storedHash string
-	err := ps.pool.QueryRow(ctx, query, url).Scan(&storedHash)
+	err = ps.pool.QueryRow(ctx, query, canonicalKey).Scan(&storedHash)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -325,6 +384,470 @@ func (ps *PostgresStorage) CheckContentChange(ctx context.Context, url, contentH
 	return storedHash != contentHash, nil
 }
 
+// GetRevalidationHeaders returns the ETag and Last-Modified validators
+// stored from the last successful crawl of url, so the caller can issue a
+// conditional GET. Both are empty if the page hasn't been crawled before or
+// the server never sent them. Matches on canonical_url_key so a syntactic
+// variant of a previously-seen URL still finds the stored validators.
+func (ps *PostgresStorage) GetRevalidationHeaders(ctx context.Context, url string) (etag, lastModified string, err error) {
+	canonicalKey, err := ps.normalizer.Normalize(url)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to normalize URL: %w", err)
+	}
+
+	query := `
+		SELECT etag, last_modified
+		FROM page_metadata
+		WHERE canonical_url_key = $1
+	`
+
+	err = ps.pool.QueryRow(ctx, query, canonicalKey).Scan(&etag, &lastModified)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("failed to get revalidation headers: %w", err)
+	}
+
+	return etag, lastModified, nil
+}
+
+// TouchPageLastSeen bumps last_seen_at for url without touching
+// change_count, for a 304 Not Modified response: the page is confirmed
+// unchanged, so it isn't a content change. Matches on canonical_url_key so
+// a syntactic variant of a previously-seen URL still touches the same row.
+func (ps *PostgresStorage) TouchPageLastSeen(ctx context.Context, url string) error {
+	canonicalKey, err := ps.normalizer.Normalize(url)
+	if err != nil {
+		return fmt.Errorf("failed to normalize URL: %w", err)
+	}
+
+	query := `
+		UPDATE page_metadata
+		SET last_seen_at = $1
+		WHERE canonical_url_key = $2
+	`
+
+	_, err = ps.pool.Exec(ctx, query, time.Now(), canonicalKey)
+	if err != nil {
+		return fmt.Errorf("failed to touch page last seen: %w", err)
+	}
+
+	return nil
+}
+
+// ListTrackedURLs returns the distinct URLs that have completed at least
+// one crawl, the candidate set the adaptive re-crawler recomputes cadence
+// for on each tick.
+func (ps *PostgresStorage) ListTrackedURLs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT DISTINCT url
+		FROM crawl_jobs
+		WHERE status = 'completed'
+	`
+
+	rows, err := ps.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked urls: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return nil, fmt.Errorf("failed to scan tracked url: %w", err)
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, rows.Err()
+}
+
+// GetLatestCrawlJobByURL returns the most recently created crawl_jobs row
+// for url, which carries its current re-crawl schedule fields.
+func (ps *PostgresStorage) GetLatestCrawlJobByURL(ctx context.Context, url string) (*CrawlJob, error) {
+	query := `
+		SELECT id, url, domain, status, priority, depth, max_depth,
+		       created_at, updated_at, started_at, completed_at, error,
+		       next_crawl_at, interval_seconds, change_score
+		FROM crawl_jobs
+		WHERE url = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var job CrawlJob
+	err := ps.pool.QueryRow(ctx, query, url).Scan(
+		&job.ID,
+		&job.URL,
+		&job.Domain,
+		&job.Status,
+		&job.Priority,
+		&job.Depth,
+		&job.MaxDepth,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.CompletedAt,
+		&job.Error,
+		&job.NextCrawlAt,
+		&job.IntervalSeconds,
+		&job.ChangeScore,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("no crawl history for url")
+		}
+		return nil, fmt.Errorf("failed to get latest crawl job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// UpdateCrawlSchedule persists the re-crawl cadence computed by
+// internal/recrawl onto url's latest crawl_jobs row.
+func (ps *PostgresStorage) UpdateCrawlSchedule(ctx context.Context, url string, policy recrawl.Policy) error {
+	query := `
+		UPDATE crawl_jobs
+		SET next_crawl_at = $1, interval_seconds = $2, change_score = $3, updated_at = $4
+		WHERE id = (
+			SELECT id FROM crawl_jobs WHERE url = $5 ORDER BY created_at DESC LIMIT 1
+		)
+	`
+
+	_, err := ps.pool.Exec(ctx, query,
+		policy.NextCrawlAt,
+		policy.IntervalSeconds,
+		policy.ChangeScore,
+		time.Now(),
+		url,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update crawl schedule: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueCrawlJobs returns the latest crawl_jobs row for every URL whose
+// next_crawl_at has elapsed, highest priority first, for the periodic
+// re-crawler to enqueue.
+func (ps *PostgresStorage) GetDueCrawlJobs(ctx context.Context, now time.Time, limit int) ([]*CrawlJob, error) {
+	query := `
+		SELECT id, url, domain, status, priority, depth, max_depth,
+		       created_at, updated_at, started_at, completed_at, error,
+		       next_crawl_at, interval_seconds, change_score
+		FROM (
+			SELECT DISTINCT ON (url) *
+			FROM crawl_jobs
+			WHERE next_crawl_at <= $1
+			ORDER BY url, created_at DESC
+		) latest
+		ORDER BY priority DESC, next_crawl_at ASC
+		LIMIT $2
+	`
+
+	rows, err := ps.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due crawl jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*CrawlJob
+	for rows.Next() {
+		var job CrawlJob
+		if err := rows.Scan(
+			&job.ID,
+			&job.URL,
+			&job.Domain,
+			&job.Status,
+			&job.Priority,
+			&job.Depth,
+			&job.MaxDepth,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+			&job.StartedAt,
+			&job.CompletedAt,
+			&job.Error,
+			&job.NextCrawlAt,
+			&job.IntervalSeconds,
+			&job.ChangeScore,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan due crawl job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// DomainStats is one window's aggregated crawl outcome counts for a domain,
+// as persisted by SaveDomainStatsSnapshot.
+type DomainStats struct {
+	Domain       string
+	WindowStart  time.Time
+	WindowEnd    time.Time
+	Success      int64
+	ClientErrors int64
+	ServerErrors int64
+	RobotsDenied int64
+	Timeouts     int64
+	Errors       int64
+	BytesFetched int64
+	P50Millis    int64
+	P95Millis    int64
+	P99Millis    int64
+}
+
+// JobStats is one window's aggregated crawl outcome counts for a single
+// crawl job, as persisted by SaveJobStatsSnapshot.
+type JobStats struct {
+	JobID        int64
+	ParentJobID  int64
+	WindowStart  time.Time
+	WindowEnd    time.Time
+	Success      int64
+	ClientErrors int64
+	ServerErrors int64
+	RobotsDenied int64
+	Timeouts     int64
+	Errors       int64
+	BytesFetched int64
+	P50Millis    int64
+	P95Millis    int64
+	P99Millis    int64
+}
+
+// SaveDomainStatsSnapshot persists one stats.DomainSnapshot window for
+// domain, covering [windowStart, windowEnd).
+func (ps *PostgresStorage) SaveDomainStatsSnapshot(ctx context.Context, domain string, windowStart, windowEnd time.Time, snap stats.Snapshot) error {
+	query := `
+		INSERT INTO domain_crawl_stats
+			(domain, window_start, window_end, success, client_errors, server_errors,
+			 robots_denied, timeouts, errors, bytes_fetched, p50_millis, p95_millis, p99_millis)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := ps.pool.Exec(ctx, query,
+		domain, windowStart, windowEnd,
+		snap.Success, snap.ClientErrors, snap.ServerErrors,
+		snap.RobotsDenied, snap.Timeouts, snap.Errors,
+		snap.BytesFetched, snap.P50Millis, snap.P95Millis, snap.P99Millis,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save domain stats snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// SaveJobStatsSnapshot persists one stats.JobSnapshot window for snap.JobID.
+func (ps *PostgresStorage) SaveJobStatsSnapshot(ctx context.Context, windowStart, windowEnd time.Time, snap stats.JobSnapshot) error {
+	query := `
+		INSERT INTO job_crawl_stats
+			(job_id, parent_job_id, window_start, window_end, success, client_errors, server_errors,
+			 robots_denied, timeouts, errors, bytes_fetched, p50_millis, p95_millis, p99_millis)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := ps.pool.Exec(ctx, query,
+		snap.JobID, snap.ParentJobID, windowStart, windowEnd,
+		snap.Success, snap.ClientErrors, snap.ServerErrors,
+		snap.RobotsDenied, snap.Timeouts, snap.Errors,
+		snap.BytesFetched, snap.P50Millis, snap.P95Millis, snap.P99Millis,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save job stats snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// ListDomainStats returns every domain's counters summed across all
+// persisted windows. P50/P95/P99Millis are each window's own percentile,
+// aggregated with MAX() as an approximation — merging raw histograms
+// across windows would give an exact answer but isn't worth the storage
+// cost here.
+func (ps *PostgresStorage) ListDomainStats(ctx context.Context) ([]*DomainStats, error) {
+	query := `
+		SELECT domain,
+		       MIN(window_start), MAX(window_end),
+		       SUM(success), SUM(client_errors), SUM(server_errors),
+		       SUM(robots_denied), SUM(timeouts), SUM(errors), SUM(bytes_fetched),
+		       MAX(p50_millis), MAX(p95_millis), MAX(p99_millis)
+		FROM domain_crawl_stats
+		GROUP BY domain
+		ORDER BY domain
+	`
+
+	rows, err := ps.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domain stats: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*DomainStats
+	for rows.Next() {
+		var d DomainStats
+		if err := rows.Scan(
+			&d.Domain,
+			&d.WindowStart, &d.WindowEnd,
+			&d.Success, &d.ClientErrors, &d.ServerErrors,
+			&d.RobotsDenied, &d.Timeouts, &d.Errors, &d.BytesFetched,
+			&d.P50Millis, &d.P95Millis, &d.P99Millis,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan domain stats: %w", err)
+		}
+		out = append(out, &d)
+	}
+
+	return out, rows.Err()
+}
+
+// GetJobStats returns jobID's counters summed across all persisted
+// windows, following the same percentile-approximation caveat as
+// ListDomainStats.
+func (ps *PostgresStorage) GetJobStats(ctx context.Context, jobID int64) (*JobStats, error) {
+	query := `
+		SELECT job_id, MAX(parent_job_id),
+		       MIN(window_start), MAX(window_end),
+		       SUM(success), SUM(client_errors), SUM(server_errors),
+		       SUM(robots_denied), SUM(timeouts), SUM(errors), SUM(bytes_fetched),
+		       MAX(p50_millis), MAX(p95_millis), MAX(p99_millis)
+		FROM job_crawl_stats
+		WHERE job_id = $1
+		GROUP BY job_id
+	`
+
+	var j JobStats
+	err := ps.pool.QueryRow(ctx, query, jobID).Scan(
+		&j.JobID, &j.ParentJobID,
+		&j.WindowStart, &j.WindowEnd,
+		&j.Success, &j.ClientErrors, &j.ServerErrors,
+		&j.RobotsDenied, &j.Timeouts, &j.Errors, &j.BytesFetched,
+		&j.P50Millis, &j.P95Millis, &j.P99Millis,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("no stats for job")
+		}
+		return nil, fmt.Errorf("failed to get job stats: %w", err)
+	}
+
+	return &j, nil
+}
+
+// CreateAPIKey persists a new API key row and returns its ID. Only key.Hash
+// is stored — the raw token itself is never persisted.
+func (ps *PostgresStorage) CreateAPIKey(ctx context.Context, key *auth.APIKey) (int64, error) {
+	query := `
+		INSERT INTO api_keys (hash, name, scopes, rate_limit_per_min, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	var id int64
+	err := ps.pool.QueryRow(ctx, query, key.Hash, key.Name, key.Scopes, key.RateLimitPerMin, key.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetAPIKeyByHash looks up an API key by the SHA-256 hash of its token,
+// returning it regardless of revocation status — callers must check
+// APIKey.Revoked() themselves.
+func (ps *PostgresStorage) GetAPIKeyByHash(ctx context.Context, hash string) (*auth.APIKey, error) {
+	query := `
+		SELECT id, hash, name, scopes, rate_limit_per_min, created_at, revoked_at
+		FROM api_keys
+		WHERE hash = $1
+	`
+
+	var key auth.APIKey
+	err := ps.pool.QueryRow(ctx, query, hash).Scan(
+		&key.ID, &key.Hash, &key.Name, &key.Scopes, &key.RateLimitPerMin, &key.CreatedAt, &key.RevokedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("unknown api key")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// GetAPIKeyByID looks up an API key by its database ID, for cmd/crawlerctl
+// operations (rotate, revoke) that address a key by ID rather than token.
+func (ps *PostgresStorage) GetAPIKeyByID(ctx context.Context, id int64) (*auth.APIKey, error) {
+	query := `
+		SELECT id, hash, name, scopes, rate_limit_per_min, created_at, revoked_at
+		FROM api_keys
+		WHERE id = $1
+	`
+
+	var key auth.APIKey
+	err := ps.pool.QueryRow(ctx, query, id).Scan(
+		&key.ID, &key.Hash, &key.Name, &key.Scopes, &key.RateLimitPerMin, &key.CreatedAt, &key.RevokedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("unknown api key")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// RevokeAPIKey marks an API key as revoked, effective immediately.
+func (ps *PostgresStorage) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2`
+
+	_, err := ps.pool.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns every API key, including revoked ones, newest first.
+func (ps *PostgresStorage) ListAPIKeys(ctx context.Context) ([]*auth.APIKey, error) {
+	query := `
+		SELECT id, hash, name, scopes, rate_limit_per_min, created_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at DESC
+	`
+
+	rows, err := ps.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*auth.APIKey
+	for rows.Next() {
+		var key auth.APIKey
+		if err := rows.Scan(
+			&key.ID, &key.Hash, &key.Name, &key.Scopes, &key.RateLimitPerMin, &key.CreatedAt, &key.RevokedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
 // Close closes the database connection pool
 func (ps *PostgresStorage) Close() {
 	ps.pool.Close()
@@ -0,0 +1,50 @@
+package warc
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// buildRequestBlock reconstructs an HTTP/1.1 request message for a WARC
+// request record from the request line and the headers HTTPClient.Fetch
+// sent, preceded by a Host header taken from targetURL. The headers are
+// written in map iteration order, so the block is a reconstruction of the
+// request, not a byte-for-byte copy of what went out.
+func buildRequestBlock(requestLine string, headers http.Header, targetURL string) []byte {
+	var buf bytes.Buffer
+
+	host := ""
+	if parsed, err := url.Parse(targetURL); err == nil {
+		host = parsed.Host
+	}
+
+	fmt.Fprintf(&buf, "%s\r\n", requestLine)
+	fmt.Fprintf(&buf, "Host: %s\r\n", host)
+	for key, values := range headers {
+		for _, value := range values {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
+
+// buildResponseBlock reconstructs the HTTP/1.1 response message (status
+// line, headers, and body) for a WARC response record.
+func buildResponseBlock(statusCode int, headers map[string][]string, body []byte) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+	for key, values := range headers {
+		for _, value := range values {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes()
+}
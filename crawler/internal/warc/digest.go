@@ -0,0 +1,14 @@
+package warc
+
+import (
+	"crypto/sha1"
+	"encoding/base32"
+)
+
+// sha1Digest returns data's SHA-1 hash in the form WARC-Payload-Digest and
+// WARC-Block-Digest expect: "sha1:" followed by the unpadded base32
+// encoding of the raw digest.
+func sha1Digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:])
+}
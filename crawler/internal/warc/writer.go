@@ -0,0 +1,359 @@
+// Package warc writes crawl fetches as WARC 1.1 archive files, so full
+// request/response records can be replayed later by standard WARC tooling.
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Writer appends WARC 1.1 records to gzip-compressed files under baseDir,
+// rotating to a new file once the current one exceeds maxSize. Each record
+// is written as its own independent gzip member (rather than one continuous
+// gzip stream), so a reader can seek to any record's recorded offset and
+// decompress it on its own, the same layout real-world WARC tooling
+// expects. It is safe for concurrent use: a mutex serializes record framing
+// around the single underlying file handle.
+type Writer struct {
+	baseDir string
+	maxSize int64
+	logger  *zap.Logger
+
+	mu        sync.Mutex
+	file      *os.File
+	indexFile *os.File
+	written   int64
+}
+
+// PageFetch carries what WriteFetch needs to reconstruct a WARC
+// request/response record pair for a single page or asset fetch; headers
+// are written back in map order, not the order they had on the wire
+type PageFetch struct {
+	URL            string
+	RequestLine    string
+	RequestHeaders http.Header
+	StatusCode     int
+	ContentType    string
+	Headers        map[string][]string
+	Body           []byte
+	RemoteAddr     string
+	FetchedAt      time.Time
+}
+
+// NewWriter creates a WARC writer rooted at baseDir. maxSize is the
+// approximate rotation threshold in bytes; 0 defaults to 1 GiB.
+func NewWriter(baseDir string, maxSize int64, logger *zap.Logger) (*Writer, error) {
+	if maxSize <= 0 {
+		maxSize = 1 << 30 // 1 GiB
+	}
+
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create WARC directory: %w", err)
+	}
+
+	indexFile, err := os.OpenFile(filepath.Join(baseDir, "index.cdxj"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CDX-J index: %w", err)
+	}
+
+	return &Writer{
+		baseDir:   baseDir,
+		maxSize:   maxSize,
+		logger:    logger,
+		indexFile: indexFile,
+	}, nil
+}
+
+// WriteFetch writes a request record followed by a response record
+// describing fetch, then appends a CDX-J index line for the response so it
+// can be looked up by URL later without re-scanning the archive.
+func (w *Writer) WriteFetch(fetch PageFetch) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureFileLocked(); err != nil {
+		return err
+	}
+
+	reqBlock := buildRequestBlock(fetch.RequestLine, fetch.RequestHeaders, fetch.URL)
+	if _, _, err := w.writeRecordLocked("request", fetch.URL, fetch.FetchedAt, reqBlock, nil); err != nil {
+		return err
+	}
+
+	respBlock := buildResponseBlock(fetch.StatusCode, fetch.Headers, fetch.Body)
+	payloadDigest := sha1Digest(fetch.Body)
+	extra := map[string]string{
+		"WARC-Payload-Digest": payloadDigest,
+		"WARC-Block-Digest":   sha1Digest(respBlock),
+	}
+	if fetch.RemoteAddr != "" {
+		extra["WARC-IP-Address"] = fetch.RemoteAddr
+	}
+
+	offset, length, err := w.writeRecordLocked("response", fetch.URL, fetch.FetchedAt, respBlock, extra)
+	if err != nil {
+		return err
+	}
+	// Taken after the write: writeRecordLocked may have rotated to a new file.
+	filename := filepath.Base(w.file.Name())
+
+	return w.appendCDXLocked(cdxEntry{
+		URL:      fetch.URL,
+		Date:     fetch.FetchedAt,
+		Status:   fetch.StatusCode,
+		Mime:     fetch.ContentType,
+		Digest:   payloadDigest,
+		Length:   length,
+		Offset:   offset,
+		Filename: filename,
+	})
+}
+
+// writeRecordLocked writes a single WARC 1.1 record, with its mandatory
+// headers, as its own independent gzip member, and returns the byte offset
+// in the archive file where that member begins along with its compressed
+// length. Callers must hold w.mu.
+func (w *Writer) writeRecordLocked(recordType, targetURI string, date time.Time, block []byte, extraHeaders map[string]string) (offset, length int64, err error) {
+	if err := w.ensureFileLocked(); err != nil {
+		return 0, 0, err
+	}
+
+	recordID := "<urn:uuid:" + uuid.NewString() + ">"
+
+	var header bytes.Buffer
+	header.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&header, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&header, "WARC-Record-ID: %s\r\n", recordID)
+	fmt.Fprintf(&header, "WARC-Date: %s\r\n", date.UTC().Format(time.RFC3339))
+	if targetURI != "" {
+		fmt.Fprintf(&header, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	for key, value := range extraHeaders {
+		fmt.Fprintf(&header, "%s: %s\r\n", key, value)
+	}
+	fmt.Fprintf(&header, "Content-Type: %s\r\n", contentTypeFor(recordType))
+	fmt.Fprintf(&header, "Content-Length: %d\r\n", len(block))
+	header.WriteString("\r\n")
+
+	offset = w.written
+
+	cw := &countingWriter{w: w.file}
+	gz := gzip.NewWriter(cw)
+
+	if _, err := gz.Write(header.Bytes()); err != nil {
+		return 0, 0, fmt.Errorf("failed to write WARC header: %w", err)
+	}
+	if _, err := gz.Write(block); err != nil {
+		return 0, 0, fmt.Errorf("failed to write WARC block: %w", err)
+	}
+	if _, err := gz.Write([]byte("\r\n\r\n")); err != nil {
+		return 0, 0, fmt.Errorf("failed to write WARC record terminator: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, 0, fmt.Errorf("failed to close WARC record gzip member: %w", err)
+	}
+
+	w.written += cw.count
+	return offset, cw.count, nil
+}
+
+// countingWriter tracks how many compressed bytes a single gzip member
+// writes to the underlying archive file, so writeRecordLocked can report
+// each record's offset and length for the CDX-J index.
+type countingWriter struct {
+	w     *os.File
+	count int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += int64(n)
+	return n, err
+}
+
+// cdxEntry is the information a CDX-J index line captures about one
+// archived response, enough to locate and verify it without re-scanning
+// the archive.
+type cdxEntry struct {
+	URL      string
+	Date     time.Time
+	Status   int
+	Mime     string
+	Digest   string
+	Length   int64
+	Offset   int64
+	Filename string
+}
+
+// appendCDXLocked writes one CDX-J line for entry: a SURT-form URL key, a
+// 14-digit WARC-Date timestamp, and a JSON blob carrying everything needed
+// to seek directly to the record (filename, gzip-member offset and
+// length) and verify it (status, mime, payload digest). Callers must hold
+// w.mu.
+func (w *Writer) appendCDXLocked(entry cdxEntry) error {
+	payload, err := json.Marshal(struct {
+		URL      string `json:"url"`
+		Mime     string `json:"mime"`
+		Status   int    `json:"status"`
+		Digest   string `json:"digest"`
+		Length   int64  `json:"length"`
+		Offset   int64  `json:"offset"`
+		Filename string `json:"filename"`
+	}{
+		URL:      entry.URL,
+		Mime:     entry.Mime,
+		Status:   entry.Status,
+		Digest:   entry.Digest,
+		Length:   entry.Length,
+		Offset:   entry.Offset,
+		Filename: entry.Filename,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode CDX-J entry: %w", err)
+	}
+
+	line := fmt.Sprintf("%s %s %s\n", surt(entry.URL), entry.Date.UTC().Format("20060102150405"), payload)
+	if _, err := w.indexFile.WriteString(line); err != nil {
+		return fmt.Errorf("failed to write CDX-J index: %w", err)
+	}
+	return nil
+}
+
+// surt converts rawURL to SURT form (Sort-friendly URI Reordering
+// Transform): the host's labels reversed and comma-joined, followed by the
+// path and query unchanged — e.g. "http://www.example.com/a?b=1" becomes
+// "com,example,www)/a?b=1". This is the conventional CDX index key since it
+// groups a domain (and its subdomains) together under sort order.
+func surt(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	labels := strings.Split(host, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	path := parsed.RequestURI()
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join(labels, ",") + ")" + path
+}
+
+// contentTypeFor returns the Content-Type WARC expects for each record type
+func contentTypeFor(recordType string) string {
+	switch recordType {
+	case "request":
+		return "application/http; msgtype=request"
+	case "response":
+		return "application/http; msgtype=response"
+	case "warcinfo":
+		return "application/warc-fields"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ensureFileLocked opens a fresh archive file if none is open yet, or the
+// current one has grown past maxSize. Callers must hold w.mu.
+func (w *Writer) ensureFileLocked() error {
+	if w.file != nil && w.written < w.maxSize {
+		return nil
+	}
+
+	if w.file != nil {
+		if err := w.closeCurrentLocked(); err != nil {
+			return err
+		}
+	}
+
+	date := time.Now().UTC().Format("2006-01-02")
+	name := fmt.Sprintf("crawl-%s-%s.warc.gz", date, uuid.NewString())
+	path := filepath.Join(w.baseDir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create WARC file: %w", err)
+	}
+
+	w.file = file
+	w.written = 0
+
+	w.logger.Info("opened WARC archive file", zap.String("path", path))
+
+	_, _, err = w.writeRecordLocked("warcinfo", "", time.Now(), warcInfoBlock(), nil)
+	return err
+}
+
+// closeCurrentLocked closes the current archive file. Callers must hold w.mu.
+func (w *Writer) closeCurrentLocked() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("failed to close WARC file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes the currently open archive file and the CDX-J
+// index, if open.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.closeCurrentLocked(); err != nil {
+		return err
+	}
+	if w.indexFile != nil {
+		if err := w.indexFile.Close(); err != nil {
+			return fmt.Errorf("failed to close CDX-J index: %w", err)
+		}
+	}
+	return nil
+}
+
+// List returns the paths of all archive files written so far, oldest first
+func (w *Writer) List() ([]string, error) {
+	entries, err := os.ReadDir(w.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WARC directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".warc.gz") {
+			continue
+		}
+		paths = append(paths, filepath.Join(w.baseDir, entry.Name()))
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// warcInfoBlock is the body of the warcinfo record written at the start of
+// every archive file
+func warcInfoBlock() []byte {
+	return []byte(
+		"software: seo-platform-crawler\r\n" +
+			"format: WARC File Format 1.1\r\n" +
+			"conformsTo: https://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n",
+	)
+}
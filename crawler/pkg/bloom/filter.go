@@ -1,6 +1,10 @@
 package bloom
 
 import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
 	"sync"
 
 	"github.com/bits-and-blooms/bloom/v3"
@@ -78,7 +82,7 @@ func (uf *URLFilter) Count() uint {
 	uf.mu.RLock()
 	defer uf.mu.RUnlock()
 
-	return uf.filter.ApproximatedSize()
+	return uint(uf.filter.ApproximatedSize())
 }
 
 // Clear resets the filter
@@ -96,12 +100,11 @@ func (uf *URLFilter) Stats() FilterStats {
 	uf.mu.RLock()
 	defer uf.mu.RUnlock()
 
+	n := uint(uf.filter.ApproximatedSize())
 	return FilterStats{
-		ApproximateCount: uf.filter.ApproximatedSize(),
-		Capacity:         uf.filter.Cap(),
-		FalsePositiveRate: uf.filter.EstimateFalsePositiveRate(
-			uf.filter.ApproximatedSize(),
-		),
+		ApproximateCount:  n,
+		Capacity:          uf.filter.Cap(),
+		FalsePositiveRate: bloom.EstimateFalsePositiveRate(uf.filter.Cap(), uf.filter.K(), n),
 	}
 }
 
@@ -112,32 +115,254 @@ type FilterStats struct {
 	FalsePositiveRate float64
 }
 
-// URLDeduplicator provides comprehensive URL deduplication
-// Combines Bloom filter for quick checks with a backing store for accuracy
+// Default growth/tightening constants for ScalableBloomFilter, per Almeida
+// et al.: each new tier's capacity is the previous tier's times
+// defaultGrowthFactor, and its false-positive rate is the previous tier's
+// times defaultTighteningRatio, keeping the compounded FP rate bounded by
+// p0 / (1 - defaultTighteningRatio).
+const (
+	defaultGrowthFactor    = 4.0
+	defaultTighteningRatio = 0.8
+)
+
+// ScalableBloomFilterConfig seeds NewScalableBloomFilter's first tier and
+// controls how later tiers are sized.
+type ScalableBloomFilterConfig struct {
+	// InitialCapacity is n0, the first tier's expected element count.
+	InitialCapacity uint
+	// FalsePositiveRate is p0, the first tier's target false-positive rate.
+	FalsePositiveRate float64
+	// GrowthFactor is s: tier i's capacity is InitialCapacity * s^i.
+	// Defaults to 4.
+	GrowthFactor float64
+	// TighteningRatio is r: tier i's false-positive rate is
+	// FalsePositiveRate * r^i. Defaults to 0.8.
+	TighteningRatio float64
+}
+
+func (c ScalableBloomFilterConfig) growthFactor() float64 {
+	if c.GrowthFactor <= 1 {
+		return defaultGrowthFactor
+	}
+	return c.GrowthFactor
+}
+
+func (c ScalableBloomFilterConfig) tighteningRatio() float64 {
+	if c.TighteningRatio <= 0 || c.TighteningRatio >= 1 {
+		return defaultTighteningRatio
+	}
+	return c.TighteningRatio
+}
+
+// sbfTier is one fixed-capacity filter in a ScalableBloomFilter's chain,
+// paired with the element capacity it was sized for (bloom.BloomFilter.Cap
+// reports bit-array size, not element count, so it can't stand in for this).
+type sbfTier struct {
+	filter   *bloom.BloomFilter
+	capacity uint
+}
+
+// ScalableBloomFilter is an effectively-unbounded-capacity Bloom filter,
+// built from Almeida et al.'s Scalable Bloom Filter: a growing chain of
+// fixed-capacity filters, each sized and false-positive-tightened so the
+// compounded error rate stays bounded however many elements are added.
+// Contains reports true if any tier matches; Add only ever writes to the
+// newest (active) tier, growing the chain first if that tier is full.
+type ScalableBloomFilter struct {
+	mu      sync.RWMutex
+	filters []*sbfTier
+	config  ScalableBloomFilterConfig
+}
+
+// NewScalableBloomFilter creates a ScalableBloomFilter with one tier sized
+// per config.
+func NewScalableBloomFilter(config ScalableBloomFilterConfig) *ScalableBloomFilter {
+	sbf := &ScalableBloomFilter{config: config}
+	sbf.filters = []*sbfTier{sbf.newTier(0)}
+	return sbf
+}
+
+func (sbf *ScalableBloomFilter) newTier(i int) *sbfTier {
+	capacity := uint(float64(sbf.config.InitialCapacity) * math.Pow(sbf.config.growthFactor(), float64(i)))
+	fp := sbf.config.FalsePositiveRate * math.Pow(sbf.config.tighteningRatio(), float64(i))
+	return &sbfTier{
+		filter:   bloom.NewWithEstimates(capacity, fp),
+		capacity: capacity,
+	}
+}
+
+// Add inserts url into the active tier, appending a new, larger, tighter
+// tier first if the active one has reached its configured capacity.
+func (sbf *ScalableBloomFilter) Add(url string) {
+	sbf.mu.Lock()
+	defer sbf.mu.Unlock()
+
+	active := sbf.filters[len(sbf.filters)-1]
+	if uint(active.filter.ApproximatedSize()) >= active.capacity {
+		active = sbf.newTier(len(sbf.filters))
+		sbf.filters = append(sbf.filters, active)
+	}
+	active.filter.AddString(url)
+}
+
+// Contains reports whether url was probably added: true if any tier in the
+// chain matches, false only if every tier agrees it was never added.
+func (sbf *ScalableBloomFilter) Contains(url string) bool {
+	sbf.mu.RLock()
+	defer sbf.mu.RUnlock()
+
+	for _, tier := range sbf.filters {
+		if tier.filter.TestString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the approximate total number of elements added, summed
+// across every tier.
+func (sbf *ScalableBloomFilter) Count() uint {
+	sbf.mu.RLock()
+	defer sbf.mu.RUnlock()
+
+	var total uint
+	for _, tier := range sbf.filters {
+		total += uint(tier.filter.ApproximatedSize())
+	}
+	return total
+}
+
+// Tiers returns the number of filters currently in the chain.
+func (sbf *ScalableBloomFilter) Tiers() int {
+	sbf.mu.RLock()
+	defer sbf.mu.RUnlock()
+
+	return len(sbf.filters)
+}
+
+// Serialize writes every tier's capacity and bit-set to w, in chain order,
+// so Deserialize can reconstruct the exact same filter chain.
+func (sbf *ScalableBloomFilter) Serialize(w io.Writer) error {
+	sbf.mu.RLock()
+	defer sbf.mu.RUnlock()
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(sbf.filters))); err != nil {
+		return fmt.Errorf("write tier count: %w", err)
+	}
+
+	for i, tier := range sbf.filters {
+		if err := binary.Write(w, binary.BigEndian, uint64(tier.capacity)); err != nil {
+			return fmt.Errorf("write tier %d capacity: %w", i, err)
+		}
+		if _, err := tier.filter.WriteTo(w); err != nil {
+			return fmt.Errorf("write tier %d filter: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Deserialize replaces sbf's filter chain with the one encoded by r (as
+// written by Serialize), so dedup state survives a crawler restart.
+func (sbf *ScalableBloomFilter) Deserialize(r io.Reader) error {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return fmt.Errorf("read tier count: %w", err)
+	}
+
+	filters := make([]*sbfTier, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var capacity uint64
+		if err := binary.Read(r, binary.BigEndian, &capacity); err != nil {
+			return fmt.Errorf("read tier %d capacity: %w", i, err)
+		}
+
+		filter := &bloom.BloomFilter{}
+		if _, err := filter.ReadFrom(r); err != nil {
+			return fmt.Errorf("read tier %d filter: %w", i, err)
+		}
+
+		filters = append(filters, &sbfTier{filter: filter, capacity: uint(capacity)})
+	}
+
+	sbf.mu.Lock()
+	sbf.filters = filters
+	sbf.mu.Unlock()
+
+	return nil
+}
+
+// URLDeduplicatorConfig configures NewURLDeduplicator.
+type URLDeduplicatorConfig struct {
+	// InitialCapacity and FalsePositiveRate seed the backing
+	// ScalableBloomFilter's first tier (n0, p0).
+	InitialCapacity   uint
+	FalsePositiveRate float64
+	// GrowthFactor and TighteningRatio tune how later tiers are sized; see
+	// ScalableBloomFilterConfig. Both default when left zero.
+	GrowthFactor    float64
+	TighteningRatio float64
+	// ExactVerify, when true, also maintains a backing map so IsSeen never
+	// reports a false positive. That map grows without bound, defeating
+	// the whole point of the Bloom filter, so it's opt-in for callers that
+	// truly need zero false positives and can afford the memory.
+	ExactVerify bool
+}
+
+// URLDeduplicator provides comprehensive URL deduplication, backed by a
+// ScalableBloomFilter so memory stays bounded across a real crawl (hundreds
+// of millions of URLs) instead of growing with an exact-match map.
 type URLDeduplicator struct {
-	bloom  *URLFilter
-	store  map[string]bool
-	mu     sync.RWMutex
-	logger *zap.Logger
+	sbf         *ScalableBloomFilter
+	exactVerify bool
+	store       map[string]bool
+	mu          sync.RWMutex
+	logger      *zap.Logger
 }
 
-// NewURLDeduplicator creates a new URL deduplicator
-func NewURLDeduplicator(bloomCapacity uint, bloomFP float64, logger *zap.Logger) *URLDeduplicator {
-	return &URLDeduplicator{
-		bloom:  NewURLFilter(bloomCapacity, bloomFP, logger),
-		store:  make(map[string]bool),
-		logger: logger,
+// NewURLDeduplicator creates a new URL deduplicator per config.
+func NewURLDeduplicator(config URLDeduplicatorConfig, logger *zap.Logger) *URLDeduplicator {
+	ud := &URLDeduplicator{
+		sbf: NewScalableBloomFilter(ScalableBloomFilterConfig{
+			InitialCapacity:   config.InitialCapacity,
+			FalsePositiveRate: config.FalsePositiveRate,
+			GrowthFactor:      config.GrowthFactor,
+			TighteningRatio:   config.TighteningRatio,
+		}),
+		exactVerify: config.ExactVerify,
+		logger:      logger,
+	}
+	if config.ExactVerify {
+		ud.store = make(map[string]bool)
 	}
+
+	logger.Info("created URL deduplicator",
+		zap.Uint("initial_capacity", config.InitialCapacity),
+		zap.Float64("false_positive_rate", config.FalsePositiveRate),
+		zap.Bool("exact_verify", config.ExactVerify),
+	)
+
+	return ud
+}
+
+// filter returns the current Scalable Bloom Filter chain. ud.mu is held
+// just long enough to read the pointer, since Clear reassigns it; the
+// filter's own internal locking covers everything called on the result.
+func (ud *URLDeduplicator) filter() *ScalableBloomFilter {
+	ud.mu.RLock()
+	defer ud.mu.RUnlock()
+	return ud.sbf
 }
 
 // IsSeen checks if a URL has been seen before
 func (ud *URLDeduplicator) IsSeen(url string) bool {
-	// Quick check with Bloom filter first
-	if !ud.bloom.Contains(url) {
+	if !ud.filter().Contains(url) {
 		return false
 	}
 
-	// Verify with actual store to eliminate false positives
+	if !ud.exactVerify {
+		return true
+	}
+
 	ud.mu.RLock()
 	defer ud.mu.RUnlock()
 
@@ -146,7 +371,11 @@ func (ud *URLDeduplicator) IsSeen(url string) bool {
 
 // MarkSeen marks a URL as seen
 func (ud *URLDeduplicator) MarkSeen(url string) {
-	ud.bloom.Add(url)
+	ud.filter().Add(url)
+
+	if !ud.exactVerify {
+		return
+	}
 
 	ud.mu.Lock()
 	defer ud.mu.Unlock()
@@ -156,7 +385,14 @@ func (ud *URLDeduplicator) MarkSeen(url string) {
 
 // MarkSeenBatch marks multiple URLs as seen
 func (ud *URLDeduplicator) MarkSeenBatch(urls []string) {
-	ud.bloom.AddBatch(urls)
+	filter := ud.filter()
+	for _, url := range urls {
+		filter.Add(url)
+	}
+
+	if !ud.exactVerify {
+		return
+	}
 
 	ud.mu.Lock()
 	defer ud.mu.Unlock()
@@ -166,22 +402,31 @@ func (ud *URLDeduplicator) MarkSeenBatch(urls []string) {
 	}
 }
 
-// Count returns the exact number of seen URLs
+// Count returns the approximate number of seen URLs. With ExactVerify it's
+// exact (the backing map's size); otherwise it's the Scalable Bloom
+// Filter's approximation.
 func (ud *URLDeduplicator) Count() int {
+	if !ud.exactVerify {
+		return int(ud.filter().Count())
+	}
+
 	ud.mu.RLock()
 	defer ud.mu.RUnlock()
 
 	return len(ud.store)
 }
 
-// Clear resets both the Bloom filter and the store
+// Clear resets both the Bloom filter chain and the backing store, if any,
+// swapping both under one write lock so a concurrent IsSeen/MarkSeen never
+// reads a store that's been reset against the old filter chain, or vice
+// versa.
 func (ud *URLDeduplicator) Clear() {
-	ud.bloom.Clear()
-
 	ud.mu.Lock()
-	defer ud.mu.Unlock()
-
-	ud.store = make(map[string]bool)
+	ud.sbf = NewScalableBloomFilter(ud.sbf.config)
+	if ud.exactVerify {
+		ud.store = make(map[string]bool)
+	}
+	ud.mu.Unlock()
 
 	ud.logger.Info("cleared URL deduplicator")
 }
@@ -199,26 +444,43 @@ func (ud *URLDeduplicator) FilterUnseen(urls []string) []string {
 	return unseen
 }
 
+// Serialize writes the deduplicator's Bloom filter chain to w. The
+// ExactVerify backing map, if any, is intentionally not persisted: it's
+// meant for small, process-lifetime-only dedup sets, not durable state.
+func (ud *URLDeduplicator) Serialize(w io.Writer) error {
+	return ud.filter().Serialize(w)
+}
+
+// Deserialize restores the deduplicator's Bloom filter chain from r, as
+// written by Serialize, so dedup state survives a crawler restart. With
+// ExactVerify on, the backing map is not restored and IsSeen also checks
+// it, so URLs seen before the restart read as unseen until added again.
+func (ud *URLDeduplicator) Deserialize(r io.Reader) error {
+	return ud.filter().Deserialize(r)
+}
+
 // Stats returns deduplicator statistics
 func (ud *URLDeduplicator) Stats() DeduplicatorStats {
-	bloomStats := ud.bloom.Stats()
-
-	ud.mu.RLock()
-	storeSize := len(ud.store)
-	ud.mu.RUnlock()
+	storeSize := 0
+	if ud.exactVerify {
+		ud.mu.RLock()
+		storeSize = len(ud.store)
+		ud.mu.RUnlock()
+	}
 
+	filter := ud.filter()
 	return DeduplicatorStats{
-		StoreSize:         storeSize,
-		BloomCount:        bloomStats.ApproximateCount,
-		BloomCapacity:     bloomStats.Capacity,
-		BloomFPRate:       bloomStats.FalsePositiveRate,
+		StoreSize:   storeSize,
+		BloomCount:  filter.Count(),
+		BloomTiers:  filter.Tiers(),
+		ExactVerify: ud.exactVerify,
 	}
 }
 
 // DeduplicatorStats represents deduplicator statistics
 type DeduplicatorStats struct {
-	StoreSize     int
-	BloomCount    uint
-	BloomCapacity uint
-	BloomFPRate   float64
+	StoreSize   int
+	BloomCount  uint
+	BloomTiers  int
+	ExactVerify bool
 }
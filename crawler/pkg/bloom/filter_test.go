@@ -0,0 +1,218 @@
+package bloom
+
+import (
+	"bytes"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestScalableBloomFilter_ContainsAddedURLs covers that every URL added to a
+// ScalableBloomFilter is reported as contained, including across a tier
+// growth boundary, while a never-added URL is not.
+func TestScalableBloomFilter_ContainsAddedURLs(t *testing.T) {
+	sbf := NewScalableBloomFilter(ScalableBloomFilterConfig{
+		InitialCapacity:   4,
+		FalsePositiveRate: 0.01,
+	})
+
+	added := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	for _, url := range added {
+		sbf.Add(url)
+	}
+
+	for _, url := range added {
+		if !sbf.Contains(url) {
+			t.Errorf("Contains(%q) = false after Add(%q); want true", url, url)
+		}
+	}
+
+	if sbf.Contains("never-added") {
+		t.Error("Contains(\"never-added\") = true; want false")
+	}
+
+	if got := sbf.Count(); got < uint(len(added)) {
+		t.Errorf("Count() = %d; want at least %d", got, len(added))
+	}
+
+	if tiers := sbf.Tiers(); tiers < 2 {
+		t.Errorf("Tiers() = %d after exceeding initial capacity; want >= 2", tiers)
+	}
+}
+
+// TestScalableBloomFilter_SerializeDeserialize covers that a round trip
+// through Serialize/Deserialize preserves every added URL's membership.
+func TestScalableBloomFilter_SerializeDeserialize(t *testing.T) {
+	sbf := NewScalableBloomFilter(ScalableBloomFilterConfig{
+		InitialCapacity:   4,
+		FalsePositiveRate: 0.01,
+	})
+
+	added := []string{"a", "b", "c", "d", "e", "f"}
+	for _, url := range added {
+		sbf.Add(url)
+	}
+
+	var buf bytes.Buffer
+	if err := sbf.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize() returned unexpected error: %v", err)
+	}
+
+	restored := NewScalableBloomFilter(ScalableBloomFilterConfig{InitialCapacity: 4, FalsePositiveRate: 0.01})
+	if err := restored.Deserialize(&buf); err != nil {
+		t.Fatalf("Deserialize() returned unexpected error: %v", err)
+	}
+
+	for _, url := range added {
+		if !restored.Contains(url) {
+			t.Errorf("restored.Contains(%q) = false; want true", url)
+		}
+	}
+	if got, want := restored.Tiers(), sbf.Tiers(); got != want {
+		t.Errorf("restored.Tiers() = %d; want %d", got, want)
+	}
+}
+
+// TestURLDeduplicator_IsSeen covers the basic MarkSeen/IsSeen contract, with
+// and without ExactVerify.
+func TestURLDeduplicator_IsSeen(t *testing.T) {
+	tests := []struct {
+		name        string
+		exactVerify bool
+	}{
+		{name: "bloom-only", exactVerify: false},
+		{name: "exact verify", exactVerify: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ud := NewURLDeduplicator(URLDeduplicatorConfig{
+				InitialCapacity:   16,
+				FalsePositiveRate: 0.01,
+				ExactVerify:       tt.exactVerify,
+			}, zap.NewNop())
+
+			if ud.IsSeen("http://example.com/a") {
+				t.Error("IsSeen() = true before MarkSeen; want false")
+			}
+
+			ud.MarkSeen("http://example.com/a")
+
+			if !ud.IsSeen("http://example.com/a") {
+				t.Error("IsSeen() = false after MarkSeen; want true")
+			}
+			if ud.IsSeen("http://example.com/b") {
+				t.Error("IsSeen() = true for a URL never marked seen; want false")
+			}
+			if got := ud.Count(); got != 1 {
+				t.Errorf("Count() = %d after one MarkSeen; want 1", got)
+			}
+		})
+	}
+}
+
+// TestURLDeduplicator_MarkSeenBatch covers that every URL in a batch is
+// marked seen and counted.
+func TestURLDeduplicator_MarkSeenBatch(t *testing.T) {
+	ud := NewURLDeduplicator(URLDeduplicatorConfig{
+		InitialCapacity:   16,
+		FalsePositiveRate: 0.01,
+		ExactVerify:       true,
+	}, zap.NewNop())
+
+	urls := []string{"http://example.com/a", "http://example.com/b", "http://example.com/c"}
+	ud.MarkSeenBatch(urls)
+
+	for _, url := range urls {
+		if !ud.IsSeen(url) {
+			t.Errorf("IsSeen(%q) = false after MarkSeenBatch; want true", url)
+		}
+	}
+	if got := ud.Count(); got != len(urls) {
+		t.Errorf("Count() = %d; want %d", got, len(urls))
+	}
+}
+
+// TestURLDeduplicator_FilterUnseen covers that FilterUnseen keeps only URLs
+// not already marked seen.
+func TestURLDeduplicator_FilterUnseen(t *testing.T) {
+	ud := NewURLDeduplicator(URLDeduplicatorConfig{
+		InitialCapacity:   16,
+		FalsePositiveRate: 0.01,
+	}, zap.NewNop())
+
+	ud.MarkSeen("http://example.com/a")
+
+	got := ud.FilterUnseen([]string{"http://example.com/a", "http://example.com/b", "http://example.com/c"})
+	want := []string{"http://example.com/b", "http://example.com/c"}
+
+	if len(got) != len(want) {
+		t.Fatalf("FilterUnseen() = %v; want %v", got, want)
+	}
+	for i, url := range want {
+		if got[i] != url {
+			t.Errorf("FilterUnseen()[%d] = %q; want %q", i, got[i], url)
+		}
+	}
+}
+
+// TestURLDeduplicator_Clear covers that Clear resets both the Bloom filter
+// and, with ExactVerify, the backing store, so a previously-seen URL reads
+// as unseen afterward.
+func TestURLDeduplicator_Clear(t *testing.T) {
+	ud := NewURLDeduplicator(URLDeduplicatorConfig{
+		InitialCapacity:   16,
+		FalsePositiveRate: 0.01,
+		ExactVerify:       true,
+	}, zap.NewNop())
+
+	ud.MarkSeen("http://example.com/a")
+	if !ud.IsSeen("http://example.com/a") {
+		t.Fatal("IsSeen() = false right after MarkSeen; want true")
+	}
+
+	ud.Clear()
+
+	if ud.IsSeen("http://example.com/a") {
+		t.Error("IsSeen() = true after Clear; want false")
+	}
+	if got := ud.Count(); got != 0 {
+		t.Errorf("Count() = %d after Clear; want 0", got)
+	}
+}
+
+// TestURLDeduplicator_Stats covers that Stats reports the backing store size
+// only when ExactVerify is set.
+func TestURLDeduplicator_Stats(t *testing.T) {
+	tests := []struct {
+		name          string
+		exactVerify   bool
+		wantStoreSize int
+	}{
+		{name: "bloom-only reports zero store size", exactVerify: false, wantStoreSize: 0},
+		{name: "exact verify reports store size", exactVerify: true, wantStoreSize: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ud := NewURLDeduplicator(URLDeduplicatorConfig{
+				InitialCapacity:   16,
+				FalsePositiveRate: 0.01,
+				ExactVerify:       tt.exactVerify,
+			}, zap.NewNop())
+
+			ud.MarkSeenBatch([]string{"http://example.com/a", "http://example.com/b"})
+
+			stats := ud.Stats()
+			if stats.StoreSize != tt.wantStoreSize {
+				t.Errorf("Stats().StoreSize = %d; want %d", stats.StoreSize, tt.wantStoreSize)
+			}
+			if stats.ExactVerify != tt.exactVerify {
+				t.Errorf("Stats().ExactVerify = %v; want %v", stats.ExactVerify, tt.exactVerify)
+			}
+			if stats.BloomCount == 0 {
+				t.Error("Stats().BloomCount = 0 after marking two URLs; want nonzero")
+			}
+		})
+	}
+}